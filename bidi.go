@@ -0,0 +1,172 @@
+package gocui
+
+import (
+	"golang.org/x/text/unicode/bidi"
+)
+
+// brackets that are displayed mirrored in right-to-left text
+var mirroredRunes = map[rune]rune{
+	'(': ')', ')': '(',
+	'[': ']', ']': '[',
+	'{': '}', '}': '{',
+	'<': '>', '>': '<',
+	'«': '»', '»': '«',
+}
+
+// bidiLayout is the visual order of the cells of a line, as determined by the
+// Unicode bidirectional algorithm.
+type bidiLayout struct {
+	// order holds the indices of the cells in the order they are displayed in
+	order []int
+	// rtl tells, for each cell, whether it's part of right-to-left text
+	rtl []bool
+}
+
+// newBidiLayout lays out a line of cells. It returns nil if the line contains
+// no right-to-left text, in which case it is displayed as is.
+//
+// The embedding levels are approximated by the paragraph level and the
+// direction of each run, which is enough for text without explicit
+// directional formatting characters.
+func newBidiLayout(line []cell) *bidiLayout {
+	runes := make([]rune, len(line))
+	hasRTL := false
+	paragraphRTL := false
+	foundStrong := false
+	for i, c := range line {
+		runes[i] = c.chr
+		props, _ := bidi.LookupRune(c.chr)
+		switch props.Class() {
+		case bidi.R, bidi.AL:
+			hasRTL = true
+			if !foundStrong {
+				paragraphRTL = true
+				foundStrong = true
+			}
+		case bidi.L:
+			foundStrong = true
+		}
+	}
+	if !hasRTL {
+		return nil
+	}
+
+	var p bidi.Paragraph
+	opts := []bidi.Option{}
+	if paragraphRTL {
+		opts = append(opts, bidi.DefaultDirection(bidi.RightToLeft))
+	}
+	if _, err := p.SetString(string(runes), opts...); err != nil {
+		return nil
+	}
+	ordering, err := p.Order()
+	if err != nil {
+		return nil
+	}
+
+	layout := &bidiLayout{
+		order: make([]int, 0, len(line)),
+		rtl:   make([]bool, len(line)),
+	}
+	for i := 0; i < ordering.NumRuns(); i++ {
+		runIdx := i
+		if paragraphRTL {
+			// in a right-to-left paragraph, the runs are displayed from right
+			// to left as well
+			runIdx = ordering.NumRuns() - 1 - i
+		}
+		run := ordering.Run(runIdx)
+		start, end := run.Pos()
+		if run.Direction() == bidi.RightToLeft {
+			for j := end; j >= start; j-- {
+				layout.order = append(layout.order, j)
+				layout.rtl[j] = true
+			}
+		} else {
+			for j := start; j <= end; j++ {
+				layout.order = append(layout.order, j)
+			}
+		}
+	}
+
+	return layout
+}
+
+// displayedRune returns the rune to display for the cell at the given index,
+// which differs from the stored one for mirrored brackets.
+func (l *bidiLayout) displayedRune(line []cell, idx int) rune {
+	if l.rtl[idx] {
+		if mirrored, ok := mirroredRunes[line[idx].chr]; ok {
+			return mirrored
+		}
+	}
+
+	return line[idx].chr
+}
+
+// mapColumn converts a column in the line from logical to visual order, or
+// the other way around. Columns past the end of the line are left alone.
+func (l *bidiLayout) mapColumn(line []cell, x int, toVisual bool) int {
+	logicalStart := make([]int, len(line))
+	col := 0
+	for i, c := range line {
+		logicalStart[i] = col
+		col += c.width()
+	}
+	if x >= col {
+		return x
+	}
+
+	visualStart := make([]int, len(line))
+	col = 0
+	for _, i := range l.order {
+		visualStart[i] = col
+		col += line[i].width()
+	}
+
+	from, to := logicalStart, visualStart
+	if !toVisual {
+		from, to = visualStart, logicalStart
+	}
+	for i, c := range line {
+		if x >= from[i] && x < from[i]+c.width() {
+			return to[i]
+		}
+	}
+
+	return x
+}
+
+// bidiLayoutAt returns the layout of the view line displayed at the given row
+// of the view port, or nil if it needs none.
+func (v *View) bidiLayoutAt(y int) (*bidiLayout, []cell) {
+	if !v.Bidi || y+v.oy < 0 || y+v.oy >= len(v.viewLines) {
+		return nil, nil
+	}
+
+	line := v.viewLines[y+v.oy].line
+	return newBidiLayout(line), line
+}
+
+// visualCursorX returns the column of the view port at which the cursor is
+// displayed. It differs from the cursor's column if the line contains
+// right-to-left text.
+func (v *View) visualCursorX() int {
+	layout, line := v.bidiLayoutAt(v.cy)
+	if layout == nil {
+		return v.cx
+	}
+
+	return layout.mapColumn(line, v.cx+v.ox, true) - v.ox
+}
+
+// logicalX converts a column of the view port, e.g. where the mouse was
+// clicked, to the corresponding column in the logical order of the line.
+func (v *View) logicalX(x int, y int) int {
+	layout, line := v.bidiLayoutAt(y)
+	if layout == nil {
+		return x
+	}
+
+	return layout.mapColumn(line, x+v.ox, false) - v.ox
+}
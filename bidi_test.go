@@ -0,0 +1,88 @@
+package gocui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBidiLayout(t *testing.T) {
+	tests := []struct {
+		name           string
+		line           string
+		expectedVisual string
+		expectNoLayout bool
+	}{
+		{
+			name:           "left-to-right only",
+			line:           "hello world",
+			expectNoLayout: true,
+		},
+		{
+			name:           "right-to-left text in a left-to-right paragraph",
+			line:           "abc שלום",
+			expectedVisual: "abc םולש",
+		},
+		{
+			name:           "left-to-right text in a right-to-left paragraph",
+			line:           "שלום abc",
+			expectedVisual: "abc םולש",
+		},
+		{
+			name:           "numbers keep their order",
+			line:           "שלום 123",
+			expectedVisual: "123 םולש",
+		},
+		{
+			name:           "brackets are mirrored",
+			line:           "(שלום)",
+			expectedVisual: "(םולש)",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			line := stringToCells(test.line)
+			layout := newBidiLayout(line)
+			if test.expectNoLayout {
+				assert.Nil(t, layout)
+				return
+			}
+
+			visual := []rune{}
+			for _, idx := range layout.order {
+				visual = append(visual, layout.displayedRune(line, idx))
+			}
+			assert.EqualValues(t, test.expectedVisual, string(visual))
+		})
+	}
+}
+
+func TestBidiCursor(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 20, Height: 5})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	v, _ := g.SetView("name", 0, 0, 10, 2, 0)
+	v.Bidi = true
+	v.WriteString("ab אבג")
+	v.draw()
+
+	displayed := ""
+	for x := 1; x <= 6; x++ {
+		mainc, _, _, _ := Screen.GetContent(x, 1)
+		displayed += string(mainc)
+	}
+	assert.EqualValues(t, "ab גבא", displayed)
+
+	// the cursor is on א, which is displayed at the right end
+	v.SetCursor(3, 0)
+	assert.EqualValues(t, 5, v.visualCursorX())
+	x, _ := v.SelectedPoint()
+	assert.EqualValues(t, 3, x)
+
+	// clicking on ג selects it
+	assert.EqualValues(t, 5, v.logicalX(3, 0))
+	assert.EqualValues(t, 1, v.logicalX(1, 0))
+	assert.EqualValues(t, 8, v.logicalX(8, 0))
+}
@@ -0,0 +1,92 @@
+package gocui
+
+import (
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Clipboard is used to exchange text with the system clipboard.
+type Clipboard interface {
+	Copy(text string) error
+	Paste() (string, error)
+}
+
+// OSC52Clipboard puts copied text on the system clipboard by sending an OSC 52
+// escape sequence to the terminal, which also works over ssh. Terminals
+// generally don't let applications read the clipboard, so Paste returns the
+// text that was last copied.
+type OSC52Clipboard struct {
+	screen tcell.Screen
+	mutex  sync.Mutex
+	text   string
+}
+
+func NewOSC52Clipboard(screen tcell.Screen) *OSC52Clipboard {
+	return &OSC52Clipboard{screen: screen}
+}
+
+func (c *OSC52Clipboard) Copy(text string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.text = text
+	c.screen.SetClipboard([]byte(text))
+	return nil
+}
+
+func (c *OSC52Clipboard) Paste() (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.text, nil
+}
+
+// MemoryClipboard keeps copied text in memory without involving the terminal.
+// It is used for headless guis.
+type MemoryClipboard struct {
+	mutex sync.Mutex
+	text  string
+}
+
+func (c *MemoryClipboard) Copy(text string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.text = text
+	return nil
+}
+
+func (c *MemoryClipboard) Paste() (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.text, nil
+}
+
+// Clipboard returns the clipboard used by the gui.
+func (g *Gui) Clipboard() Clipboard {
+	return g.clipboard
+}
+
+// SetClipboard replaces the clipboard used by the gui and by the text areas of
+// its views.
+func (g *Gui) SetClipboard(clipboard Clipboard) {
+	g.Mutexes.ViewsMutex.Lock()
+	defer g.Mutexes.ViewsMutex.Unlock()
+
+	g.clipboard = clipboard
+	for _, v := range g.views {
+		v.TextArea.Clipboard = clipboard
+	}
+}
+
+// CopyToClipboard puts the given text on the clipboard, e.g. the selected
+// text of a view.
+func (g *Gui) CopyToClipboard(text string) error {
+	if g.clipboard == nil {
+		return nil
+	}
+
+	return g.clipboard.Copy(text)
+}
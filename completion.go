@@ -0,0 +1,275 @@
+package gocui
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// CompletionViewName is the name of the view that shows completion candidates.
+// It can be looked up with Gui.View while completion is in progress, e.g. to
+// change its colors.
+const CompletionViewName = "gocui.completion"
+
+// the maximum number of candidates shown at once
+const completionMaxHeight = 10
+
+// Candidate is a possible completion of the text before the cursor.
+type Candidate struct {
+	// Text replaces the word being completed.
+	Text string
+	// Description, if set, is shown next to the text in the popup.
+	Description string
+}
+
+// Completer finds the candidates for completing an editable view's input.
+// Attach it to a view by setting View.Completer; Tab then opens a popup at
+// the text cursor listing the candidates. While the popup is open, Tab, Down,
+// Shift+Tab and Up select a candidate, typing filters them, Enter accepts the
+// selected one and Esc closes the popup.
+type Completer interface {
+	// Complete returns the candidates for the given content and cursor, which
+	// is a position in content counted in runes, together with the position
+	// where the word being completed starts. Accepting a candidate replaces
+	// the runes between that position and the cursor.
+	Complete(content string, cursor int) (candidates []Candidate, start int)
+}
+
+// The CompleterFunc type is an adapter to allow the use of ordinary functions
+// as Completers.
+type CompleterFunc func(content string, cursor int) ([]Candidate, int)
+
+// Complete calls f(content, cursor)
+func (f CompleterFunc) Complete(content string, cursor int) ([]Candidate, int) {
+	return f(content, cursor)
+}
+
+// WordCompleter returns a Completer that completes the word before the cursor
+// with those of the given words that start with it.
+func WordCompleter(words []string) Completer {
+	return CompleterFunc(func(content string, cursor int) ([]Candidate, int) {
+		runes := []rune(content)
+		start := clampCursor(cursor, len(runes))
+		for start > 0 && !strings.ContainsRune(WHITESPACES+"\n", runes[start-1]) {
+			start--
+		}
+
+		prefix := string(runes[start:cursor])
+		candidates := []Candidate{}
+		for _, word := range words {
+			if strings.HasPrefix(word, prefix) {
+				candidates = append(candidates, Candidate{Text: word})
+			}
+		}
+
+		return candidates, start
+	})
+}
+
+type completion struct {
+	view       *View
+	candidates []Candidate
+	start      int
+	selected   int
+
+	// the content and cursor the candidates were found for
+	content string
+	cursor  int
+}
+
+// Completing reports whether the completion popup is open.
+func (g *Gui) Completing() bool {
+	return g.completion != nil
+}
+
+// StartCompletion looks for candidates completing the input of the given view
+// using its Completer. A single candidate is inserted right away; if there
+// are several, the completion popup is opened.
+func (g *Gui) StartCompletion(v *View) error {
+	if v.Completer == nil {
+		return nil
+	}
+
+	content, cursor := v.TextArea.GetUnwrappedContent(), v.TextArea.GetCursor()
+	candidates, start := v.Completer.Complete(content, cursor)
+	switch len(candidates) {
+	case 0:
+		return g.CloseCompletion()
+	case 1:
+		insertCandidate(v, start, candidates[0])
+		return g.CloseCompletion()
+	}
+
+	g.completion = &completion{
+		view:       v,
+		candidates: candidates,
+		start:      start,
+		content:    content,
+		cursor:     cursor,
+	}
+
+	return g.layoutCompletion()
+}
+
+// AcceptCompletion inserts the selected candidate and closes the popup.
+func (g *Gui) AcceptCompletion() error {
+	c := g.completion
+	if c == nil {
+		return nil
+	}
+
+	insertCandidate(c.view, c.start, c.candidates[c.selected])
+	return g.CloseCompletion()
+}
+
+// CloseCompletion closes the completion popup without inserting anything.
+func (g *Gui) CloseCompletion() error {
+	if g.completion == nil {
+		return nil
+	}
+
+	g.completion = nil
+	if err := g.DeleteView(CompletionViewName); err != nil && !IsUnknownView(err) {
+		return err
+	}
+
+	return nil
+}
+
+func insertCandidate(v *View, start int, candidate Candidate) {
+	ta := v.TextArea
+	defer ta.trackEdit(editOther)()
+
+	if start = clampCursor(start, ta.cursor); start < ta.cursor {
+		ta.Select(start, ta.cursor)
+		ta.DeleteSelection()
+	}
+	ta.TypeString(candidate.Text)
+	v.RenderTextArea()
+}
+
+// onCompletionKey handles the keys that open the completion popup and
+// navigate it. It returns false if the key should be handled as usual.
+func (g *Gui) onCompletionKey(ev *GocuiEvent) (bool, error) {
+	v := g.currentView
+	key, mod := legacyKey(ev.Key, ev.Mod)
+	if v == nil || !v.Editable || v.Completer == nil || mod != ModNone {
+		return false, nil
+	}
+
+	c := g.completion
+	if c == nil {
+		if key == KeyTab {
+			return true, g.StartCompletion(v)
+		}
+		return false, nil
+	}
+
+	switch key {
+	case KeyTab, KeyArrowDown:
+		c.selected = (c.selected + 1) % len(c.candidates)
+	case KeyBacktab, KeyArrowUp:
+		c.selected = (c.selected + len(c.candidates) - 1) % len(c.candidates)
+	case KeyEnter:
+		return true, g.AcceptCompletion()
+	case KeyEsc:
+		return true, g.CloseCompletion()
+	default:
+		return false, nil
+	}
+
+	return true, g.layoutCompletion()
+}
+
+// updateCompletion filters the candidates after the input has changed, and
+// closes the popup if none are left or the view has lost the focus.
+func (g *Gui) updateCompletion() error {
+	c := g.completion
+	if c == nil {
+		return nil
+	}
+
+	v := c.view
+	if g.currentView != v || !v.Editable || v.Completer == nil {
+		return g.CloseCompletion()
+	}
+
+	content, cursor := v.TextArea.GetUnwrappedContent(), v.TextArea.GetCursor()
+	if content == c.content && cursor == c.cursor {
+		return nil
+	}
+
+	candidates, start := v.Completer.Complete(content, cursor)
+	if len(candidates) == 0 {
+		return g.CloseCompletion()
+	}
+
+	c.candidates = candidates
+	c.start = start
+	c.selected = 0
+	c.content = content
+	c.cursor = cursor
+
+	return g.layoutCompletion()
+}
+
+// layoutCompletion positions the popup below the word being completed, or
+// above it if there is no room below, and renders the candidates.
+func (g *Gui) layoutCompletion() error {
+	c := g.completion
+	v := c.view
+
+	lines := make([]string, len(c.candidates))
+	textWidth := 0
+	for _, candidate := range c.candidates {
+		textWidth = max(textWidth, runewidth.StringWidth(candidate.Text))
+	}
+	width := 0
+	for i, candidate := range c.candidates {
+		lines[i] = candidate.Text
+		if candidate.Description != "" {
+			lines[i] += strings.Repeat(" ", textWidth-runewidth.StringWidth(candidate.Text)+2) + candidate.Description
+		}
+		width = max(width, runewidth.StringWidth(lines[i]))
+	}
+	height := min(len(lines), completionMaxHeight)
+
+	// align the candidates with the start of the word being completed
+	wordWidth := 0
+	if word := []rune(c.content)[clampCursor(c.start, c.cursor):c.cursor]; !strings.ContainsRune(string(word), '\n') {
+		wordWidth = runewidth.StringWidth(string(word))
+	}
+	cursorX := v.x0 + 1 + v.cx
+	cursorY := v.y0 + 1 + v.cy
+
+	x0 := max(v.x0, cursorX-wordWidth-1)
+	x1 := x0 + width + 1
+	if x1 >= g.maxX {
+		x1 = g.maxX - 1
+		x0 = max(0, x1-width-1)
+	}
+	y0 := cursorY + 1
+	y1 := y0 + height + 1
+	if y1 >= g.maxY {
+		y1 = cursorY - 1
+		y0 = max(0, y1-height-1)
+	}
+
+	popup, err := g.SetView(CompletionViewName, x0, y0, x1, y1, 0)
+	if err != nil {
+		if !IsUnknownView(err) {
+			return err
+		}
+		popup.Frame = true
+		popup.Highlight = true
+		popup.Layer = LayerPopup
+	}
+	if _, err := g.SetViewOnTop(CompletionViewName); err != nil {
+		return err
+	}
+
+	popup.SetContent(strings.Join(lines, "\n"))
+	popup.FocusPoint(0, c.selected)
+
+	return nil
+}
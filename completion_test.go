@@ -0,0 +1,145 @@
+package gocui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWordCompleter(t *testing.T) {
+	completer := WordCompleter([]string{"apple", "apricot", "banana"})
+
+	tests := []struct {
+		name               string
+		content            string
+		cursor             int
+		expectedCandidates []Candidate
+		expectedStart      int
+	}{
+		{
+			name:               "empty",
+			content:            "",
+			cursor:             0,
+			expectedCandidates: []Candidate{{Text: "apple"}, {Text: "apricot"}, {Text: "banana"}},
+			expectedStart:      0,
+		},
+		{
+			name:               "prefix",
+			content:            "eat ap",
+			cursor:             6,
+			expectedCandidates: []Candidate{{Text: "apple"}, {Text: "apricot"}},
+			expectedStart:      4,
+		},
+		{
+			name:               "only the part before the cursor counts",
+			content:            "eat banana",
+			cursor:             5,
+			expectedCandidates: []Candidate{{Text: "banana"}},
+			expectedStart:      4,
+		},
+		{
+			name:               "no match",
+			content:            "cherry",
+			cursor:             6,
+			expectedCandidates: []Candidate{},
+			expectedStart:      0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			candidates, start := completer.Complete(test.content, test.cursor)
+			assert.EqualValues(t, test.expectedCandidates, candidates)
+			assert.EqualValues(t, test.expectedStart, start)
+		})
+	}
+}
+
+func TestCompletionPopup(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 80, Height: 24})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	v, _ := g.SetView("input", 0, 0, 40, 2, 0)
+	v.Editable = true
+	v.Completer = WordCompleter([]string{"apple", "apricot", "avocado", "banana"})
+	_, err = g.SetCurrentView("input")
+	assert.NoError(t, err)
+
+	typeKey := func(key Key, ch rune) {
+		assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Key: key, Ch: ch}))
+	}
+
+	typeKey(0, 'a')
+	assert.False(t, g.Completing())
+
+	typeKey(KeyTab, 0)
+	assert.True(t, g.Completing())
+	popup, err := g.View(CompletionViewName)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"apple", "apricot", "avocado"}, popup.BufferLines())
+	// the popup is below the cursor, aligned with the word being completed
+	x0, y0, _, _, _ := g.ViewPosition(CompletionViewName)
+	assert.EqualValues(t, 0, x0)
+	assert.EqualValues(t, 2, y0)
+
+	// typing filters the candidates
+	typeKey(0, 'p')
+	assert.EqualValues(t, []string{"apple", "apricot"}, popup.BufferLines())
+
+	typeKey(KeyArrowDown, 0)
+	typeKey(KeyEnter, 0)
+	assert.False(t, g.Completing())
+	assert.EqualValues(t, "apricot", v.TextArea.GetUnwrappedContent())
+	_, err = g.View(CompletionViewName)
+	assert.True(t, IsUnknownView(err))
+
+	// a single candidate is inserted right away
+	typeKey(KeySpace, 0)
+	typeKey(0, 'b')
+	typeKey(KeyTab, 0)
+	assert.False(t, g.Completing())
+	assert.EqualValues(t, "apricot banana", v.TextArea.GetUnwrappedContent())
+
+	// no candidates left closes the popup
+	typeKey(KeySpace, 0)
+	typeKey(KeyTab, 0)
+	assert.True(t, g.Completing())
+	typeKey(0, 'x')
+	assert.False(t, g.Completing())
+
+	// escape closes the popup without inserting anything
+	typeKey(KeyBackspace2, 0)
+	typeKey(KeyTab, 0)
+	assert.True(t, g.Completing())
+	typeKey(KeyEsc, 0)
+	assert.False(t, g.Completing())
+	assert.EqualValues(t, "apricot banana ", v.TextArea.GetUnwrappedContent())
+}
+
+func TestCompletionSetManager(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 80, Height: 24})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	v, _ := g.SetView("input", 0, 0, 40, 2, 0)
+	v.Editable = true
+	v.Completer = WordCompleter([]string{"apple", "apricot"})
+	_, err = g.SetCurrentView("input")
+	assert.NoError(t, err)
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Ch: 'a'}))
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Key: KeyTab}))
+	assert.True(t, g.Completing())
+
+	// a new manager closes the popup, so that it stops capturing keys
+	g.SetManagerFunc(func(*Gui) error { return nil })
+	assert.False(t, g.Completing())
+
+	fired := false
+	assert.NoError(t, g.SetKeybinding("", KeyEnter, ModNone, func(*Gui, *View) error {
+		fired = true
+		return nil
+	}))
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Key: KeyEnter}))
+	assert.True(t, fired)
+}
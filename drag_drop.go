@@ -0,0 +1,292 @@
+package gocui
+
+import (
+	"github.com/mattn/go-runewidth"
+)
+
+// DragViewName is the name of the view that shows the label of the payload
+// next to the pointer during a drag-and-drop.
+const DragViewName = "gocui.drag"
+
+// DragPayload is what is dragged from a drag source to a drop target.
+type DragPayload struct {
+	// Label is shown next to the pointer during the drag.
+	Label string
+	// Data is passed as is to the drop target, e.g. the item of a list.
+	Data interface{}
+}
+
+// DragSource makes a view a source of drag-and-drop: dragging its content
+// with the left button drags a payload, instead of selecting text or firing
+// the drag bindings of the view.
+type DragSource struct {
+	ViewName string
+	// Payload is called when a drag starts in the view, and returns the
+	// payload for the line the button was pressed on, which is at OriginY
+	// in opts. If it returns nil, the view handles the drag as usual.
+	Payload func(opts ViewMouseBindingOpts) (*DragPayload, error)
+}
+
+// DragOpts are passed to the callbacks of a drop target.
+type DragOpts struct {
+	// Source is the view the payload is dragged from.
+	Source  *View
+	Payload *DragPayload
+
+	// X and Y are the position of the pointer relative to the content of the
+	// target, like in ViewMouseBindingOpts.
+	X int
+	Y int
+}
+
+// DropTarget makes a view a target of drag-and-drop. The source view may be
+// a target too, e.g. to reorder a list.
+type DropTarget struct {
+	ViewName string
+	// OnHover, if set, is called each time the pointer moves over the view
+	// during a drag, e.g. to highlight the line under it.
+	OnHover func(opts DragOpts) error
+	// OnLeave, if set, is called when the pointer moves away from the view
+	// during a drag, or when the drag is cancelled over it.
+	OnLeave func(opts DragOpts) error
+	// OnDrop is called when the payload is dropped on the view.
+	OnDrop func(opts DragOpts) error
+}
+
+// drag is a drag-and-drop in progress.
+type drag struct {
+	source  *View
+	payload *DragPayload
+	// the drop target under the pointer, if any
+	target     *DropTarget
+	targetView *View
+}
+
+// SetDragSource makes a view a source of drag-and-drop, replacing the drag
+// source it already is, if any. The view must exist; its drag source is
+// deleted with it.
+func (g *Gui) SetDragSource(source *DragSource) error {
+	if _, err := g.View(source.ViewName); err != nil {
+		return err
+	}
+
+	g.DeleteDragSource(source.ViewName)
+	g.dragSources = append(g.dragSources, source)
+
+	return nil
+}
+
+// DeleteDragSource stops a view from being a source of drag-and-drop.
+func (g *Gui) DeleteDragSource(viewName string) {
+	var s []*DragSource
+	for _, source := range g.dragSources {
+		if source.ViewName != viewName {
+			s = append(s, source)
+		}
+	}
+	g.dragSources = s
+}
+
+// SetDropTarget makes a view a target of drag-and-drop, replacing the drop
+// target it already is, if any. The view must exist; its drop target is
+// deleted with it.
+func (g *Gui) SetDropTarget(target *DropTarget) error {
+	if _, err := g.View(target.ViewName); err != nil {
+		return err
+	}
+
+	g.DeleteDropTarget(target.ViewName)
+	g.dropTargets = append(g.dropTargets, target)
+
+	return nil
+}
+
+// DeleteDropTarget stops a view from being a target of drag-and-drop. If the
+// payload being dragged is over the view, its callbacks aren't called anymore.
+func (g *Gui) DeleteDropTarget(viewName string) {
+	var s []*DropTarget
+	for _, target := range g.dropTargets {
+		if target.ViewName != viewName {
+			s = append(s, target)
+		}
+	}
+	g.dropTargets = s
+
+	if g.drag != nil && g.drag.targetView != nil && g.drag.targetView.name == viewName {
+		g.drag.target, g.drag.targetView = nil, nil
+	}
+}
+
+// Dragging returns the payload being dragged, or nil if no drag-and-drop is
+// in progress.
+func (g *Gui) Dragging() *DragPayload {
+	if g.drag == nil {
+		return nil
+	}
+	return g.drag.payload
+}
+
+// CancelDrag stops the drag-and-drop in progress without dropping the
+// payload, e.g. from a keybinding of KeyEsc.
+func (g *Gui) CancelDrag() error {
+	d := g.drag
+	if d == nil {
+		return nil
+	}
+	if err := g.endDrag(); err != nil {
+		return err
+	}
+	return d.leave(d.targetView, nil)
+}
+
+func (g *Gui) dragSource(v *View) *DragSource {
+	for _, source := range g.dragSources {
+		if source.ViewName == v.name {
+			return source
+		}
+	}
+	return nil
+}
+
+// dropTargetAt returns the drop target under the pointer, and its view.
+func (g *Gui) dropTargetAt(x, y int) (*DropTarget, *View) {
+	v, err := g.VisibleViewByPosition(x, y)
+	if err != nil || g.modalBlocks(v) {
+		return nil, nil
+	}
+	for _, target := range g.dropTargets {
+		if target.ViewName == v.name {
+			return target, v
+		}
+	}
+	return nil, nil
+}
+
+// opts returns the options passed to the callbacks of the drop target view
+// for the event.
+func (d *drag) opts(v *View, ev *GocuiEvent) DragOpts {
+	opts := DragOpts{Source: d.source, Payload: d.payload}
+	if ev != nil {
+		opts.X, opts.Y = v.mousePosition(ev.MouseX, ev.MouseY)
+	}
+	return opts
+}
+
+// leave calls the OnLeave callback of the drop target, if any.
+func (d *drag) leave(v *View, ev *GocuiEvent) error {
+	if d.target == nil || d.target.OnLeave == nil || v == nil {
+		return nil
+	}
+	return d.target.OnLeave(d.opts(v, ev))
+}
+
+// onDrag turns the drag events of a drag source into a drag-and-drop. It
+// returns true if the event was part of one.
+func (g *Gui) onDrag(v *View, ev *GocuiEvent) (bool, error) {
+	switch ev.MouseEvent {
+	case MouseEventDragStart:
+		if v == nil || ev.Key != MouseLeft {
+			return false, nil
+		}
+		source := g.dragSource(v)
+		if source == nil || source.Payload == nil {
+			return false, nil
+		}
+		payload, err := source.Payload(v.mouseBindingOpts(ev))
+		if err != nil || payload == nil {
+			return false, err
+		}
+		g.drag = &drag{source: v, payload: payload}
+		return true, g.moveDrag(ev)
+	case MouseEventDragMove:
+		if g.drag == nil {
+			return false, nil
+		}
+		return true, g.moveDrag(ev)
+	case MouseEventDragEnd:
+		if g.drag == nil {
+			return false, nil
+		}
+		return true, g.drop(ev)
+	}
+	return false, nil
+}
+
+// moveDrag moves the label of the payload with the pointer, and calls the
+// callbacks of the drop targets it leaves and moves over.
+func (g *Gui) moveDrag(ev *GocuiEvent) error {
+	d := g.drag
+	target, v := g.dropTargetAt(ev.MouseX, ev.MouseY)
+	if v != d.targetView {
+		if err := d.leave(d.targetView, ev); err != nil {
+			return err
+		}
+	}
+	d.target, d.targetView = target, v
+
+	if err := g.showDragLabel(ev.MouseX, ev.MouseY); err != nil {
+		return err
+	}
+	if target != nil && target.OnHover != nil {
+		return target.OnHover(d.opts(v, ev))
+	}
+	return nil
+}
+
+// drop drops the payload on the drop target under the pointer, if any.
+func (g *Gui) drop(ev *GocuiEvent) error {
+	d := g.drag
+	if err := g.endDrag(); err != nil {
+		return err
+	}
+
+	target, v := g.dropTargetAt(ev.MouseX, ev.MouseY)
+	if v != d.targetView {
+		if err := d.leave(d.targetView, ev); err != nil {
+			return err
+		}
+	}
+	if target == nil || target.OnDrop == nil {
+		return nil
+	}
+	return target.OnDrop(d.opts(v, ev))
+}
+
+// endDrag forgets the drag-and-drop in progress and deletes the label of
+// its payload.
+func (g *Gui) endDrag() error {
+	g.drag = nil
+	if err := g.DeleteView(DragViewName); err != nil && !IsUnknownView(err) {
+		return err
+	}
+	return nil
+}
+
+// showDragLabel shows the label of the payload on the line below the pointer,
+// or above it at the bottom of the screen.
+func (g *Gui) showDragLabel(mx, my int) error {
+	label := g.drag.payload.Label
+	width := runewidth.StringWidth(label)
+
+	x0 := max(-1, min(mx, g.maxX-width-1))
+	y0 := my
+	if my+1 >= g.maxY {
+		y0 = my - 2
+	}
+
+	v, err := g.SetView(DragViewName, x0, y0, x0+width+1, y0+2, 0)
+	if err != nil {
+		if !IsUnknownView(err) {
+			return err
+		}
+		v.Frame = false
+		v.FgColor = ColorDefault | AttrReverse
+		v.Layer = LayerTooltip
+	}
+	if _, err := g.SetViewOnTop(DragViewName); err != nil {
+		return err
+	}
+
+	v.SetContent(label)
+	return nil
+}
@@ -0,0 +1,214 @@
+package gocui
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDragAndDrop(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 80, Height: 24})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	files, err := g.SetView("files", 0, 0, 39, 10, 0)
+	if !IsUnknownView(err) {
+		assert.NoError(t, err)
+	}
+	files.SetContent("a.txt\nb.txt")
+	if _, err := g.SetView("trash", 40, 0, 79, 10, 0); !IsUnknownView(err) {
+		assert.NoError(t, err)
+	}
+
+	items := []string{"a.txt", "b.txt"}
+	assert.NoError(t, g.SetDragSource(&DragSource{
+		ViewName: "files",
+		Payload: func(opts ViewMouseBindingOpts) (*DragPayload, error) {
+			if opts.OriginY < 0 || opts.OriginY >= len(items) {
+				return nil, nil
+			}
+			return &DragPayload{Label: items[opts.OriginY], Data: opts.OriginY}, nil
+		},
+	}))
+	handled := []string{}
+	assert.NoError(t, g.SetDropTarget(&DropTarget{
+		ViewName: "trash",
+		OnHover: func(opts DragOpts) error {
+			handled = append(handled, fmt.Sprintf("hover %s %d,%d", opts.Payload.Label, opts.X, opts.Y))
+			return nil
+		},
+		OnLeave: func(opts DragOpts) error {
+			handled = append(handled, fmt.Sprintf("leave %s", opts.Payload.Label))
+			return nil
+		},
+		OnDrop: func(opts DragOpts) error {
+			handled = append(handled, fmt.Sprintf("drop %s %v from %s at %d,%d", opts.Payload.Label, opts.Payload.Data, opts.Source.Name(), opts.X, opts.Y))
+			return nil
+		},
+	}))
+
+	mouse := func(x, y int, buttons tcell.ButtonMask) {
+		ev := g.mouseState.event(x, y, buttons, tcell.ModNone, time.Now(), g.DoubleClickInterval)
+		if ev.Type != eventNone {
+			assert.NoError(t, g.onKey(&ev))
+		}
+	}
+
+	// a drag of the second line, dropped on the trash
+	mouse(3, 2, tcell.ButtonPrimary)
+	mouse(20, 2, tcell.ButtonPrimary)
+	assert.Equal(t, "b.txt", g.Dragging().Label)
+	label, err := g.View(DragViewName)
+	assert.NoError(t, err)
+	assert.Equal(t, "b.txt", label.Buffer())
+	x0, y0, _, _, _ := g.ViewPosition(DragViewName)
+	assert.Equal(t, []int{20, 2}, []int{x0, y0})
+	v, _ := g.VisibleViewByPosition(20, 2)
+	assert.Equal(t, files, v)
+
+	mouse(45, 3, tcell.ButtonPrimary)
+	mouse(46, 4, tcell.ButtonPrimary)
+	mouse(46, 4, tcell.ButtonNone)
+	assert.EqualValues(t, []string{
+		"hover b.txt 4,2",
+		"hover b.txt 5,3",
+		"drop b.txt 1 from files at 5,3",
+	}, handled)
+	assert.Nil(t, g.Dragging())
+	_, err = g.View(DragViewName)
+	assert.True(t, IsUnknownView(err))
+
+	// a drag that leaves the trash and ends elsewhere
+	handled = []string{}
+	mouse(3, 1, tcell.ButtonPrimary)
+	mouse(45, 1, tcell.ButtonPrimary)
+	mouse(20, 1, tcell.ButtonPrimary)
+	mouse(20, 1, tcell.ButtonNone)
+	assert.EqualValues(t, []string{
+		"hover a.txt 4,0",
+		"leave a.txt",
+	}, handled)
+
+	// a cancelled drag
+	handled = []string{}
+	mouse(3, 1, tcell.ButtonPrimary)
+	mouse(45, 1, tcell.ButtonPrimary)
+	assert.NoError(t, g.CancelDrag())
+	mouse(45, 1, tcell.ButtonNone)
+	assert.EqualValues(t, []string{
+		"hover a.txt 4,0",
+		"leave a.txt",
+	}, handled)
+	assert.Nil(t, g.Dragging())
+
+	// a drag from an empty line isn't a drag-and-drop
+	mouse(3, 5, tcell.ButtonPrimary)
+	mouse(45, 5, tcell.ButtonPrimary)
+	mouse(45, 5, tcell.ButtonNone)
+	assert.Nil(t, g.Dragging())
+	assert.Equal(t, 2, len(handled))
+}
+
+func TestDragAndDropSetManager(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 80, Height: 24})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	if _, err := g.SetView("files", 0, 0, 39, 10, 0); !IsUnknownView(err) {
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, g.SetDragSource(&DragSource{
+		ViewName: "files",
+		Payload: func(opts ViewMouseBindingOpts) (*DragPayload, error) {
+			return &DragPayload{Label: "a.txt"}, nil
+		},
+	}))
+
+	mouse := func(x, y int, buttons tcell.ButtonMask) {
+		ev := g.mouseState.event(x, y, buttons, tcell.ModNone, time.Now(), g.DoubleClickInterval)
+		if ev.Type != eventNone {
+			assert.NoError(t, g.onKey(&ev))
+		}
+	}
+	mouse(3, 1, tcell.ButtonPrimary)
+	mouse(20, 1, tcell.ButtonPrimary)
+	assert.NotNil(t, g.Dragging())
+
+	// a new manager drops the drag-and-drop and its sources and targets
+	g.SetManagerFunc(func(*Gui) error { return nil })
+	assert.Nil(t, g.Dragging())
+	assert.Empty(t, g.dragSources)
+	assert.Empty(t, g.dropTargets)
+	mouse(25, 1, tcell.ButtonPrimary)
+	mouse(25, 1, tcell.ButtonNone)
+	_, err = g.View(DragViewName)
+	assert.True(t, IsUnknownView(err))
+}
+
+func TestDragAndDropRegistration(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 80, Height: 24})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	payload := func(opts ViewMouseBindingOpts) (*DragPayload, error) {
+		return &DragPayload{Label: "a.txt"}, nil
+	}
+	dropped := 0
+	onDrop := func(opts DragOpts) error {
+		dropped++
+		return nil
+	}
+
+	// the views must exist
+	assert.True(t, IsUnknownView(g.SetDragSource(&DragSource{ViewName: "files", Payload: payload})))
+	assert.True(t, IsUnknownView(g.SetDropTarget(&DropTarget{ViewName: "trash", OnDrop: onDrop})))
+
+	if _, err := g.SetView("files", 0, 0, 39, 10, 0); !IsUnknownView(err) {
+		assert.NoError(t, err)
+	}
+	if _, err := g.SetView("trash", 40, 0, 79, 10, 0); !IsUnknownView(err) {
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, g.SetDragSource(&DragSource{ViewName: "files", Payload: payload}))
+	assert.NoError(t, g.SetDropTarget(&DropTarget{ViewName: "trash", OnDrop: onDrop}))
+	// setting them again replaces them
+	assert.NoError(t, g.SetDragSource(&DragSource{ViewName: "files", Payload: payload}))
+	assert.NoError(t, g.SetDropTarget(&DropTarget{ViewName: "trash", OnDrop: onDrop}))
+	assert.Len(t, g.dragSources, 1)
+	assert.Len(t, g.dropTargets, 1)
+
+	mouse := func(x, y int, buttons tcell.ButtonMask) {
+		ev := g.mouseState.event(x, y, buttons, tcell.ModNone, time.Now(), g.DoubleClickInterval)
+		if ev.Type != eventNone {
+			assert.NoError(t, g.onKey(&ev))
+		}
+	}
+	drag := func() {
+		mouse(3, 1, tcell.ButtonPrimary)
+		mouse(45, 1, tcell.ButtonPrimary)
+		mouse(45, 1, tcell.ButtonNone)
+	}
+	drag()
+	assert.Equal(t, 1, dropped)
+
+	assert.NoError(t, g.SetDropTarget(&DropTarget{ViewName: "trash", OnDrop: onDrop}))
+	g.DeleteDropTarget("trash")
+	drag()
+	assert.Equal(t, 1, dropped)
+
+	assert.NoError(t, g.SetDropTarget(&DropTarget{ViewName: "trash", OnDrop: onDrop}))
+	g.DeleteDragSource("files")
+	drag()
+	assert.Nil(t, g.Dragging())
+	assert.Equal(t, 1, dropped)
+
+	// deleting a view deletes its registrations
+	assert.NoError(t, g.SetDragSource(&DragSource{ViewName: "files", Payload: payload}))
+	assert.NoError(t, g.DeleteView("files"))
+	assert.NoError(t, g.DeleteView("trash"))
+	assert.Empty(t, g.dragSources)
+	assert.Empty(t, g.dropTargets)
+}
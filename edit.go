@@ -26,24 +26,99 @@ func (f EditorFunc) Edit(v *View, key Key, ch rune, mod Modifier) bool {
 // DefaultEditor is the default editor.
 var DefaultEditor Editor = EditorFunc(SimpleEditor)
 
+// EditorKey is a key press that an editor maps to one of its actions.
+type EditorKey struct {
+	Key Key
+	Ch  rune
+	Mod Modifier
+}
+
+// Matches reports whether the given key press is the EditorKey.
+func (k EditorKey) Matches(key Key, ch rune, mod Modifier) bool {
+	return k.Key == key && k.Ch == ch && k.Mod == mod
+}
+
+// EditorKeys holds the keys of editor actions that have no universally agreed
+// upon binding.
+type EditorKeys struct {
+	Undo          EditorKey
+	Redo          EditorKey
+	Cut           EditorKey
+	Copy          EditorKey
+	YankPop       EditorKey
+	HistorySearch EditorKey
+}
+
+// SimpleEditorKeys are the keys used by SimpleEditor. Change them to rebind
+// the corresponding actions.
+var SimpleEditorKeys = EditorKeys{
+	Undo:          EditorKey{Key: KeyCtrlZ},
+	Redo:          EditorKey{Ch: 'z', Mod: ModAlt},
+	Cut:           EditorKey{Key: KeyCtrlX},
+	Copy:          EditorKey{Ch: 'w', Mod: ModAlt},
+	YankPop:       EditorKey{Ch: 'y', Mod: ModAlt},
+	HistorySearch: EditorKey{Key: KeyCtrlR},
+}
+
 // SimpleEditor is used as the default gocui editor.
 func SimpleEditor(v *View, key Key, ch rune, mod Modifier) bool {
+	key, mod = legacyKey(key, mod)
+
+	if v.History != nil && v.History.Searching() && v.History.searchKey(v.TextArea, key, ch, mod) {
+		v.RenderTextArea()
+		return true
+	}
+
 	switch {
+	case SimpleEditorKeys.Undo.Matches(key, ch, mod):
+		v.TextArea.Undo()
+	case SimpleEditorKeys.Redo.Matches(key, ch, mod):
+		v.TextArea.Redo()
+	case v.TextArea.HasSelection() && SimpleEditorKeys.Cut.Matches(key, ch, mod):
+		v.TextArea.CutSelection()
+	case v.TextArea.HasSelection() && SimpleEditorKeys.Copy.Matches(key, ch, mod):
+		v.TextArea.CopySelection()
+	case SimpleEditorKeys.YankPop.Matches(key, ch, mod):
+		v.TextArea.YankPop()
 	case key == KeyBackspace || key == KeyBackspace2:
 		v.TextArea.BackSpaceChar()
 	case key == KeyCtrlD || key == KeyDelete:
 		v.TextArea.DeleteChar()
+	case v.History != nil && SimpleEditorKeys.HistorySearch.Matches(key, ch, mod):
+		v.History.Search(v.TextArea)
+	case key == KeyArrowDown && v.History != nil && v.TextArea.onLastLine():
+		v.History.Next(v.TextArea)
+	case key == KeyArrowUp && v.History != nil && v.TextArea.onFirstLine():
+		v.History.Prev(v.TextArea)
 	case key == KeyArrowDown:
+		v.TextArea.ClearSelection()
+		v.TextArea.MoveCursorDown()
+	case key == KeyShiftArrowDown:
+		v.TextArea.StartSelection()
 		v.TextArea.MoveCursorDown()
 	case key == KeyArrowUp:
+		v.TextArea.ClearSelection()
+		v.TextArea.MoveCursorUp()
+	case key == KeyShiftArrowUp:
+		v.TextArea.StartSelection()
 		v.TextArea.MoveCursorUp()
-	case key == KeyArrowLeft && (mod&ModAlt) != 0:
+	case key == KeyArrowLeft && (mod&(ModAlt|ModCtrl)) != 0:
+		v.TextArea.ClearSelection()
 		v.TextArea.MoveLeftWord()
 	case key == KeyArrowLeft:
+		v.TextArea.ClearSelection()
 		v.TextArea.MoveCursorLeft()
-	case key == KeyArrowRight && (mod&ModAlt) != 0:
+	case key == KeyShiftArrowLeft:
+		v.TextArea.StartSelection()
+		v.TextArea.MoveCursorLeft()
+	case key == KeyArrowRight && (mod&(ModAlt|ModCtrl)) != 0:
+		v.TextArea.ClearSelection()
 		v.TextArea.MoveRightWord()
 	case key == KeyArrowRight:
+		v.TextArea.ClearSelection()
+		v.TextArea.MoveCursorRight()
+	case key == KeyShiftArrowRight:
+		v.TextArea.StartSelection()
 		v.TextArea.MoveCursorRight()
 	case key == KeyEnter:
 		v.TextArea.TypeRune('\n')
@@ -56,8 +131,16 @@ func SimpleEditor(v *View, key Key, ch rune, mod Modifier) bool {
 	case key == KeyCtrlK:
 		v.TextArea.DeleteToEndOfLine()
 	case key == KeyCtrlA || key == KeyHome:
+		v.TextArea.ClearSelection()
+		v.TextArea.GoToStartOfLine()
+	case key == KeyShiftHome:
+		v.TextArea.StartSelection()
 		v.TextArea.GoToStartOfLine()
 	case key == KeyCtrlE || key == KeyEnd:
+		v.TextArea.ClearSelection()
+		v.TextArea.GoToEndOfLine()
+	case key == KeyShiftEnd:
+		v.TextArea.StartSelection()
 		v.TextArea.GoToEndOfLine()
 	case key == KeyCtrlW:
 		v.TextArea.BackSpaceWord()
@@ -0,0 +1,56 @@
+// Copyright 2014 The gocui Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/gvcgo/gocui"
+)
+
+func title(title string) func(*gocui.View) error {
+	return func(v *gocui.View) error {
+		v.Title = title
+		fmt.Fprintln(v, "Resize the terminal to see the layout adapt.")
+		return nil
+	}
+}
+
+func main() {
+	g, err := gocui.NewGui(gocui.NewGuiOpts{OutputMode: gocui.OutputNormal})
+	if err != nil {
+		log.Panicln(err)
+	}
+	defer g.Close()
+
+	side := gocui.ViewBox("side", gocui.Percent(20).WithMin(15))
+	side.OnCreate = title("side")
+	main := gocui.ViewBox("main", gocui.Flex(2))
+	main.OnCreate = title("main")
+	details := gocui.ViewBox("details", gocui.Flex(1).WithMax(40))
+	details.OnCreate = title("details")
+	cmdline := gocui.ViewBox("cmdline", gocui.Fixed(3))
+	cmdline.OnCreate = title("cmdline")
+
+	g.SetManager(gocui.NewLayout(
+		gocui.Column(gocui.Flex(1),
+			gocui.Row(gocui.Flex(1), side, main, details).WithGutter(1),
+			cmdline,
+		),
+	))
+
+	if err := g.SetKeybinding("", gocui.KeyCtrlC, gocui.ModNone, quit); err != nil {
+		log.Panicln(err)
+	}
+
+	if err := g.MainLoop(); err != nil && !gocui.IsQuit(err) {
+		log.Panicln(err)
+	}
+}
+
+func quit(g *gocui.Gui, v *gocui.View) error {
+	return gocui.ErrQuit
+}
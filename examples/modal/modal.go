@@ -0,0 +1,72 @@
+// Copyright 2014 The gocui Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/gvcgo/gocui"
+)
+
+func layout(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+	if v, err := g.SetView("main", 0, 0, maxX-1, maxY-1, 0); err != nil {
+		if !gocui.IsUnknownView(err) {
+			return err
+		}
+		v.Title = "main"
+		fmt.Fprintln(v, "Press q to quit.")
+		if _, err := g.SetCurrentView("main"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func confirmQuit(g *gocui.Gui, v *gocui.View) error {
+	_, err := g.PushModal(&gocui.Modal{
+		Name:   "confirm",
+		Width:  30,
+		Height: 4,
+		Dim:    true,
+		OnCreate: func(v *gocui.View) error {
+			v.Title = "Quit?"
+			fmt.Fprintln(v, "y: quit, n: cancel")
+			if err := g.SetKeybinding("confirm", 'y', gocui.ModNone, quit); err != nil {
+				return err
+			}
+			return g.SetKeybinding("confirm", 'n', gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+				return g.PopModal()
+			})
+		},
+	})
+	return err
+}
+
+func main() {
+	g, err := gocui.NewGui(gocui.NewGuiOpts{OutputMode: gocui.OutputNormal})
+	if err != nil {
+		log.Panicln(err)
+	}
+	defer g.Close()
+
+	g.SetManagerFunc(layout)
+
+	if err := g.SetKeybinding("main", 'q', gocui.ModNone, confirmQuit); err != nil {
+		log.Panicln(err)
+	}
+	if err := g.SetKeybinding("", gocui.KeyCtrlC, gocui.ModNone, quit); err != nil {
+		log.Panicln(err)
+	}
+
+	if err := g.MainLoop(); err != nil && !gocui.IsQuit(err) {
+		log.Panicln(err)
+	}
+}
+
+func quit(g *gocui.Gui, v *gocui.View) error {
+	return gocui.ErrQuit
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"log"
+	"os"
+	"path/filepath"
 
 	"github.com/gvcgo/gocui"
 )
@@ -26,6 +28,10 @@ func main() {
 		log.Panicln(err)
 	}
 
+	if err := g.SetKeybinding("prompt", gocui.KeyEnter, gocui.ModNone, submit); err != nil {
+		log.Panicln(err)
+	}
+
 	if err := g.MainLoop(); err != nil && !gocui.IsQuit(err) {
 		log.Panicln(err)
 	}
@@ -36,6 +42,14 @@ func quit(g *gocui.Gui, v *gocui.View) error {
 	return gocui.ErrQuit
 }
 
+func submit(g *gocui.Gui, v *gocui.View) error {
+	if err := v.History.Add(v.TextArea.GetUnwrappedContent()); err != nil {
+		return err
+	}
+	v.ClearTextArea()
+	return nil
+}
+
 func layout(g *gocui.Gui) error {
 	if v, err := g.SetView("prompt", 0, 0, 10, 3, 0); err != nil {
 		if !gocui.IsUnknownView(err) {
@@ -44,7 +58,12 @@ func layout(g *gocui.Gui) error {
 		v.Title = "prompt"
 		v.Editable = true
 		v.Wrap = true
-		_, err := g.SetCurrentView(v.Name())
+		// up/down recall previous input, ctrl+r searches it
+		v.History, err = gocui.NewHistory(gocui.NewFileHistoryStore(filepath.Join(os.TempDir(), "gocui_prompt_history")))
+		if err != nil {
+			return err
+		}
+		_, err = g.SetCurrentView(v.Name())
 		if err != nil {
 			return err
 		}
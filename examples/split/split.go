@@ -0,0 +1,74 @@
+// Copyright 2014 The gocui Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/gvcgo/gocui"
+)
+
+func title(title string) func(*gocui.View) error {
+	return func(v *gocui.View) error {
+		v.Title = title
+		fmt.Fprintln(v, "Drag the dividers with the mouse,")
+		fmt.Fprintln(v, "or use Ctrl+H/Ctrl+L and Ctrl+K/Ctrl+J.")
+		return nil
+	}
+}
+
+func main() {
+	g, err := gocui.NewGui(gocui.NewGuiOpts{OutputMode: gocui.OutputNormal})
+	if err != nil {
+		log.Panicln(err)
+	}
+	defer g.Close()
+
+	g.Mouse = true
+
+	files := gocui.ViewBox("files", gocui.Flex(1).WithMin(10))
+	files.OnCreate = title("files")
+	editor := gocui.ViewBox("editor", gocui.Flex(1).WithMin(3))
+	editor.OnCreate = title("editor")
+	output := gocui.ViewBox("output", gocui.Flex(1).WithMin(3))
+	output.OnCreate = title("output")
+
+	right := gocui.SplitColumn(gocui.Flex(1), 0.7, editor, output)
+	root := gocui.SplitRow(gocui.Flex(1), 0.25, files, right)
+	g.SetManager(gocui.NewLayout(root))
+
+	bindings := []struct {
+		key   gocui.Key
+		split *gocui.Box
+		cells int
+	}{
+		{gocui.KeyCtrlH, root, -1},
+		{gocui.KeyCtrlL, root, 1},
+		{gocui.KeyCtrlK, right, -1},
+		{gocui.KeyCtrlJ, right, 1},
+	}
+	for _, b := range bindings {
+		split, cells := b.split, b.cells
+		if err := g.SetKeybinding("", b.key, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+			split.MoveDivider(cells)
+			return nil
+		}); err != nil {
+			log.Panicln(err)
+		}
+	}
+
+	if err := g.SetKeybinding("", gocui.KeyCtrlC, gocui.ModNone, quit); err != nil {
+		log.Panicln(err)
+	}
+
+	if err := g.MainLoop(); err != nil && !gocui.IsQuit(err) {
+		log.Panicln(err)
+	}
+}
+
+func quit(g *gocui.Gui, v *gocui.View) error {
+	return gocui.ErrQuit
+}
@@ -0,0 +1,435 @@
+package gocui
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// The escape sequences of the kitty keyboard protocol, see
+// https://sw.kovidgoyal.net/kitty/keyboard-protocol/. We ask the terminal to
+// disambiguate escape codes (1), to report repeats and releases (2), to
+// report alternate keys (4) and to report all keys as escape codes (8), so
+// that every key event comes with its full modifiers.
+const (
+	extendedKeysQuery = "\x1b[?u"
+	extendedKeysPush  = "\x1b[>15u"
+	extendedKeysPop   = "\x1b[<u"
+)
+
+// the modifier bits of the kitty keyboard protocol
+const (
+	extendedModShift = 1 << iota
+	extendedModAlt
+	extendedModCtrl
+	extendedModSuper
+	extendedModHyper
+	extendedModMeta
+)
+
+// the event types of the kitty keyboard protocol
+const (
+	extendedEventPress   = 1
+	extendedEventRepeat  = 2
+	extendedEventRelease = 3
+)
+
+// extendedKeyCodes maps the key codes of the kitty keyboard protocol that
+// aren't characters to keys. Keypad keys are reported as their regular
+// counterparts.
+var extendedKeyCodes = map[int]Key{
+	9:     KeyTab,
+	13:    KeyEnter,
+	27:    KeyEsc,
+	127:   KeyBackspace2,
+	57414: KeyEnter,
+	57417: KeyArrowLeft,
+	57418: KeyArrowRight,
+	57419: KeyArrowUp,
+	57420: KeyArrowDown,
+	57421: KeyPgup,
+	57422: KeyPgdn,
+	57423: KeyHome,
+	57424: KeyEnd,
+	57425: KeyInsert,
+	57426: KeyDelete,
+}
+
+// extendedKeypadRunes maps the key codes of the keypad keys that type a
+// character to that character.
+var extendedKeypadRunes = map[int]rune{
+	57409: '.',
+	57410: '/',
+	57411: '*',
+	57412: '-',
+	57413: '+',
+	57415: '=',
+	57416: ',',
+}
+
+// extendedTildeKeys maps the numbers of "CSI number ~" sequences to keys.
+var extendedTildeKeys = map[int]Key{
+	2:  KeyInsert,
+	3:  KeyDelete,
+	5:  KeyPgup,
+	6:  KeyPgdn,
+	7:  KeyHome,
+	8:  KeyEnd,
+	11: KeyF1,
+	12: KeyF2,
+	13: KeyF3,
+	14: KeyF4,
+	15: KeyF5,
+	17: KeyF6,
+	18: KeyF7,
+	19: KeyF8,
+	20: KeyF9,
+	21: KeyF10,
+	23: KeyF11,
+	24: KeyF12,
+}
+
+// extendedLetterKeys maps the final characters of "CSI 1 ; modifiers letter"
+// sequences to keys.
+var extendedLetterKeys = map[byte]Key{
+	'A': KeyArrowUp,
+	'B': KeyArrowDown,
+	'C': KeyArrowRight,
+	'D': KeyArrowLeft,
+	'H': KeyHome,
+	'F': KeyEnd,
+	'P': KeyF1,
+	'Q': KeyF2,
+	'R': KeyF3,
+	'S': KeyF4,
+}
+
+// extendedKeySequenceTimeout is how long the decoder waits for the rest of an
+// escape sequence. Terminals send them in one go, so if nothing comes in the
+// meantime, the user pressed Alt+'[' instead.
+const extendedKeySequenceTimeout = 100 * time.Millisecond
+
+// extendedKeySequenceMaxLength is the length past which what the decoder
+// collects can't be an escape sequence it knows.
+const extendedKeySequenceMaxLength = 32
+
+// extendedKeyDecoder decodes the escape sequences of the kitty keyboard
+// protocol. tcell doesn't know them, so it delivers them as Alt+'[' followed
+// by their characters, which the decoder collects until the final character
+// of the sequence. Until the terminal has answered the query of the keyboard
+// enhancement flags, only that answer is collected.
+type extendedKeyDecoder struct {
+	// the keys of the escape sequence collected so far, starting with
+	// Alt+'['
+	collected []*tcell.EventKey
+	// keys that turned out not to form an escape sequence, to be handled as
+	// usual
+	replay []*tcell.EventKey
+	// incremented for each new sequence, so that the timeout of an abandoned
+	// sequence doesn't affect the next one
+	id int
+	// supported is set to 1 when the terminal answers the query of the
+	// keyboard enhancement flags. It's read from the main loop, so it's
+	// accessed atomically.
+	supported int32
+}
+
+// extendedKeyTimeout is posted as the data of an interrupt when the decoder
+// has waited too long for the rest of the sequence with the given id.
+type extendedKeyTimeout struct {
+	id int
+}
+
+// event passes an event from tcell to the decoder. It returns false if the
+// event should be handled as usual, like feed, and gives up on the sequence
+// being collected when it times out.
+func (d *extendedKeyDecoder) event(tev tcell.Event) (GocuiEvent, bool) {
+	switch tev := tev.(type) {
+	case *tcell.EventInterrupt:
+		timeout, ok := tev.Data().(extendedKeyTimeout)
+		if !ok {
+			return GocuiEvent{}, false
+		}
+		if timeout.id == d.id && len(d.collected) > 0 {
+			d.abandon()
+		}
+		return GocuiEvent{Type: eventNone}, true
+	case *tcell.EventKey:
+		ev, ok := d.feed(tev.Key(), tev.Rune(), tev.Modifiers())
+		if len(d.collected) == 1 {
+			id, screen := d.id, Screen
+			time.AfterFunc(extendedKeySequenceTimeout, func() {
+				_ = screen.PostEvent(tcell.NewEventInterrupt(extendedKeyTimeout{id: id}))
+			})
+		}
+		return ev, ok
+	}
+
+	return GocuiEvent{}, false
+}
+
+// feed passes a key event from tcell to the decoder. It returns false if the
+// event isn't part of an escape sequence, in which case it should be handled
+// as usual. Otherwise it returns the decoded event, which has type eventNone
+// while the sequence is incomplete or if it doesn't describe a key press.
+// If the keys collected turn out not to form a sequence, they're moved to
+// replay, together with the event.
+func (d *extendedKeyDecoder) feed(key tcell.Key, ch rune, mod tcell.ModMask) (GocuiEvent, bool) {
+	none := GocuiEvent{Type: eventNone}
+
+	if len(d.collected) == 0 {
+		if key == tcell.KeyRune && ch == '[' && mod == tcell.ModAlt {
+			d.id++
+			d.collected = []*tcell.EventKey{tcell.NewEventKey(key, ch, mod)}
+			return none, true
+		}
+		return GocuiEvent{}, false
+	}
+
+	d.collected = append(d.collected, tcell.NewEventKey(key, ch, mod))
+	if key != tcell.KeyRune || mod != tcell.ModNone || ch < 0x20 || ch > 0x7e ||
+		len(d.collected) > extendedKeySequenceMaxLength {
+		// not an escape sequence after all
+		d.abandon()
+		return none, true
+	}
+	supported := atomic.LoadInt32(&d.supported) == 1
+	if !supported && len(d.collected) == 2 && ch != '?' {
+		// only the answer to the query is expected
+		d.abandon()
+		return none, true
+	}
+	if ch < 0x40 {
+		return none, true
+	}
+
+	var params strings.Builder
+	for _, ev := range d.collected[1 : len(d.collected)-1] {
+		params.WriteRune(ev.Rune())
+	}
+	final := byte(ch)
+	if strings.HasPrefix(params.String(), "?") {
+		if final != 'u' || !validExtendedParams(strings.TrimPrefix(params.String(), "?")) {
+			d.abandon()
+			return none, true
+		}
+		d.collected = nil
+		atomic.StoreInt32(&d.supported, 1)
+		return none, true
+	}
+	if !supported || !validExtendedSequence(params.String(), final) {
+		d.abandon()
+		return none, true
+	}
+
+	d.collected = nil
+	return decodeExtendedKey(params.String(), final), true
+}
+
+// abandon gives up on the sequence being collected, whose keys are handled as
+// usual.
+func (d *extendedKeyDecoder) abandon() {
+	d.replay = append(d.replay, d.collected...)
+	d.collected = nil
+}
+
+// validExtendedSequence reports whether the parameters and the final
+// character have the form of a sequence the decoder knows.
+func validExtendedSequence(params string, final byte) bool {
+	if final != 'u' && final != '~' && extendedLetterKeys[final] == 0 {
+		return false
+	}
+	return validExtendedParams(params)
+}
+
+func validExtendedParams(params string) bool {
+	for _, c := range params {
+		if (c < '0' || c > '9') && c != ':' && c != ';' {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeExtendedKey decodes the parameters and the final character of a
+// "CSI key-code:shifted-key:base-key ; modifiers:event-type" sequence.
+func decodeExtendedKey(params string, final byte) GocuiEvent {
+	none := GocuiEvent{Type: eventNone}
+
+	fields := strings.Split(params, ";")
+	codes := strings.Split(fields[0], ":")
+	code, shifted := 1, 0
+	var err error
+	if codes[0] != "" {
+		if code, err = strconv.Atoi(codes[0]); err != nil {
+			return none
+		}
+	}
+	if len(codes) > 1 && codes[1] != "" {
+		if shifted, err = strconv.Atoi(codes[1]); err != nil {
+			return none
+		}
+	}
+
+	modifiers, eventType := 1, extendedEventPress
+	if len(fields) > 1 {
+		values := strings.Split(fields[1], ":")
+		if values[0] != "" {
+			if modifiers, err = strconv.Atoi(values[0]); err != nil {
+				return none
+			}
+		}
+		if len(values) > 1 && values[1] != "" {
+			if eventType, err = strconv.Atoi(values[1]); err != nil {
+				return none
+			}
+		}
+	}
+	keyEvent := KeyEventPress
+	switch eventType {
+	case extendedEventRepeat:
+		keyEvent = KeyEventRepeat
+	case extendedEventRelease:
+		keyEvent = KeyEventRelease
+	}
+
+	bits := modifiers - 1
+	mod := ModNone
+	if bits&extendedModShift != 0 {
+		mod |= ModShift
+	}
+	if bits&extendedModAlt != 0 {
+		mod |= ModAlt
+	}
+	if bits&extendedModCtrl != 0 {
+		mod |= ModCtrl
+	}
+	if bits&(extendedModSuper|extendedModHyper|extendedModMeta) != 0 {
+		mod |= ModMeta
+	}
+
+	ev := none
+	switch final {
+	case '~':
+		if key, ok := extendedTildeKeys[code]; ok {
+			ev = GocuiEvent{Type: eventKey, Key: key, Mod: mod}
+		}
+	case 'u':
+		ev = extendedCodeEvent(code, shifted, mod)
+	default:
+		if key, ok := extendedLetterKeys[final]; ok {
+			ev = GocuiEvent{Type: eventKey, Key: key, Mod: mod}
+		}
+	}
+	if ev.Type == eventKey {
+		ev.KeyEvent = keyEvent
+	}
+	return ev
+}
+
+// extendedCodeEvent returns the event of a key code, following the
+// conventions of the events of regular key presses: Ctrl with a letter is
+// reported as a control key, and Shift with a character as the shifted
+// character. Other modifiers are kept, so that e.g. Ctrl+Enter,
+// Ctrl+Shift+A or Alt+Shift+Up can be told apart.
+func extendedCodeEvent(code int, shifted int, mod Modifier) GocuiEvent {
+	if key, ok := extendedKeyCodes[code]; ok {
+		return GocuiEvent{Type: eventKey, Key: key, Mod: mod}
+	}
+
+	var ch rune
+	switch {
+	case code >= 57399 && code <= 57408:
+		ch = '0' + rune(code-57399)
+	case extendedKeypadRunes[code] != 0:
+		ch = extendedKeypadRunes[code]
+	case code >= 57344 && code <= 63743:
+		// other keys of the private use area, like the modifier keys
+		// themselves, which we don't report
+		return GocuiEvent{Type: eventNone}
+	default:
+		ch = rune(code)
+	}
+
+	if ch == ' ' {
+		if mod&ModCtrl != 0 {
+			return GocuiEvent{Type: eventKey, Key: KeyCtrlSpace, Mod: mod &^ ModCtrl}
+		}
+		return GocuiEvent{Type: eventKey, Key: KeySpace, Mod: mod}
+	}
+
+	if mod&ModCtrl != 0 {
+		if ch < unicode.MaxASCII && unicode.IsLetter(ch) {
+			return GocuiEvent{Type: eventKey, Key: KeyCtrlA + Key(unicode.ToUpper(ch)-'A'), Mod: mod &^ ModCtrl}
+		}
+		if key, ok := ctrlRunes[ch]; ok && mod&ModShift == 0 {
+			return GocuiEvent{Type: eventKey, Key: key, Mod: mod &^ ModCtrl}
+		}
+	}
+
+	if mod&ModShift != 0 {
+		switch {
+		case shifted != 0:
+			ch = rune(shifted)
+		case unicode.IsLower(ch):
+			ch = unicode.ToUpper(ch)
+		}
+		mod &^= ModShift
+	}
+	return GocuiEvent{Type: eventKey, Ch: ch, Mod: mod}
+}
+
+// legacyKey returns the key and modifier reported for a key press when
+// extended keys are disabled, which has placeholder keys for some keys
+// pressed together with Shift or Alt. Editors use it so that they handle
+// extended key presses as well. ModRepeat and ModRelease are kept.
+func legacyKey(key Key, mod Modifier) (Key, Modifier) {
+	events := mod & (ModRepeat | ModRelease)
+	switch {
+	case mod&^events == ModShift && shiftKeys[key] != 0:
+		return shiftKeys[key], events
+	case mod&^events == ModAlt && key == KeyEnter:
+		return KeyAltEnter, events
+	}
+	return key, mod
+}
+
+// extendedKey returns the key and modifier reported with extended keys for a
+// placeholder key, like KeyShiftArrowUp or KeyAltEnter, which stands for a
+// key pressed together with Shift or Alt. Keybindings use it so that their
+// placeholder keys match extended key presses as well. ModRepeat and
+// ModRelease are kept.
+func extendedKey(key Key, mod Modifier) (Key, Modifier) {
+	events := mod & (ModRepeat | ModRelease)
+	if mod&^events != ModNone {
+		return key, mod
+	}
+	if key == KeyAltEnter {
+		return KeyEnter, ModAlt | events
+	}
+	for k, shifted := range shiftKeys {
+		if shifted == key {
+			return k, ModShift | events
+		}
+	}
+	return key, mod
+}
+
+// writeExtendedKeys writes an escape sequence of the kitty keyboard protocol
+// to the terminal.
+func writeExtendedKeys(sequence string) {
+	if tty, ok := Screen.Tty(); ok {
+		_, _ = tty.Write([]byte(sequence))
+	}
+}
+
+// ExtendedKeysSupported returns whether the terminal confirmed that it
+// supports the kitty keyboard protocol, when NewGuiOpts.ExtendedKeys is set.
+// The answer comes in asynchronously, after the main loop has started.
+func (g *Gui) ExtendedKeysSupported() bool {
+	return g.extendedKeys != nil && atomic.LoadInt32(&g.extendedKeys.supported) == 1
+}
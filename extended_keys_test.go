@@ -0,0 +1,464 @@
+package gocui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// feedSequence feeds an escape sequence to the decoder the way tcell delivers
+// it: Alt+'[' followed by the characters of the sequence.
+func feedSequence(d *extendedKeyDecoder, sequence string) []GocuiEvent {
+	events := []GocuiEvent{}
+	feed := func(key tcell.Key, ch rune, mod tcell.ModMask) {
+		ev, ok := d.feed(key, ch, mod)
+		if !ok {
+			ev = GocuiEvent{Type: eventKey, Ch: ch}
+		}
+		if ev.Type != eventNone {
+			events = append(events, ev)
+		}
+	}
+	feed(tcell.KeyRune, '[', tcell.ModAlt)
+	for _, ch := range sequence[2:] {
+		feed(tcell.KeyRune, ch, tcell.ModNone)
+	}
+	return events
+}
+
+func TestExtendedKeyDecoder(t *testing.T) {
+	tests := []struct {
+		name     string
+		sequence string
+		expected []GocuiEvent
+	}{
+		{
+			name:     "character",
+			sequence: "\x1b[97u",
+			expected: []GocuiEvent{{Type: eventKey, Ch: 'a'}},
+		},
+		{
+			name:     "shifted character",
+			sequence: "\x1b[49:33;2u",
+			expected: []GocuiEvent{{Type: eventKey, Ch: '!'}},
+		},
+		{
+			name:     "Ctrl+letter",
+			sequence: "\x1b[97;5u",
+			expected: []GocuiEvent{{Type: eventKey, Key: KeyCtrlA}},
+		},
+		{
+			name:     "Ctrl+Shift+letter",
+			sequence: "\x1b[97:65;6u",
+			expected: []GocuiEvent{{Type: eventKey, Key: KeyCtrlA, Mod: ModShift}},
+		},
+		{
+			name:     "Ctrl+digit",
+			sequence: "\x1b[49;5u",
+			expected: []GocuiEvent{{Type: eventKey, Ch: '1', Mod: ModCtrl}},
+		},
+		{
+			name:     "Ctrl+Enter",
+			sequence: "\x1b[13;5u",
+			expected: []GocuiEvent{{Type: eventKey, Key: KeyEnter, Mod: ModCtrl}},
+		},
+		{
+			name:     "Alt+Enter",
+			sequence: "\x1b[13;3u",
+			expected: []GocuiEvent{{Type: eventKey, Key: KeyEnter, Mod: ModAlt}},
+		},
+		{
+			name:     "Shift+Tab",
+			sequence: "\x1b[9;2u",
+			expected: []GocuiEvent{{Type: eventKey, Key: KeyTab, Mod: ModShift}},
+		},
+		{
+			name:     "Ctrl+Space",
+			sequence: "\x1b[32;5u",
+			expected: []GocuiEvent{{Type: eventKey, Key: KeyCtrlSpace}},
+		},
+		{
+			name:     "Super+letter",
+			sequence: "\x1b[120;9u",
+			expected: []GocuiEvent{{Type: eventKey, Ch: 'x', Mod: ModMeta}},
+		},
+		{
+			name:     "Shift+Up",
+			sequence: "\x1b[1;2A",
+			expected: []GocuiEvent{{Type: eventKey, Key: KeyArrowUp, Mod: ModShift}},
+		},
+		{
+			name:     "Ctrl+Delete",
+			sequence: "\x1b[3;5~",
+			expected: []GocuiEvent{{Type: eventKey, Key: KeyDelete, Mod: ModCtrl}},
+		},
+		{
+			name:     "keypad digit",
+			sequence: "\x1b[57400u",
+			expected: []GocuiEvent{{Type: eventKey, Ch: '1'}},
+		},
+		{
+			name:     "modifier key",
+			sequence: "\x1b[57441;2u",
+			expected: []GocuiEvent{},
+		},
+		{
+			name:     "repeat",
+			sequence: "\x1b[1;1:2B",
+			expected: []GocuiEvent{{Type: eventKey, Key: KeyArrowDown, KeyEvent: KeyEventRepeat}},
+		},
+		{
+			name:     "release",
+			sequence: "\x1b[97;5:3u",
+			expected: []GocuiEvent{{Type: eventKey, Key: KeyCtrlA, KeyEvent: KeyEventRelease}},
+		},
+		{
+			name:     "support reply",
+			sequence: "\x1b[?13u",
+			expected: []GocuiEvent{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := &extendedKeyDecoder{supported: 1}
+			assert.EqualValues(t, test.expected, feedSequence(d, test.sequence))
+			assert.Empty(t, d.replay)
+		})
+	}
+}
+
+func TestExtendedKeyDecoderSupport(t *testing.T) {
+	d := &extendedKeyDecoder{}
+	g := &Gui{extendedKeys: d}
+	assert.False(t, g.ExtendedKeysSupported())
+	feedSequence(d, "\x1b[?13u")
+	assert.True(t, g.ExtendedKeysSupported())
+
+	// a key that isn't part of an escape sequence ends it, and the keys
+	// collected so far are handled as usual
+	_, ok := d.feed(tcell.KeyRune, '[', tcell.ModAlt)
+	assert.True(t, ok)
+	_, ok = d.feed(tcell.KeyEnter, 0, tcell.ModNone)
+	assert.True(t, ok)
+	assert.EqualValues(t, []string{"Alt+Rune[[]", "Enter"}, replayedKeys(d))
+	_, ok = d.feed(tcell.KeyRune, 'a', tcell.ModNone)
+	assert.False(t, ok)
+}
+
+// replayedKeys returns the names of the keys the decoder gave back, and
+// forgets them.
+func replayedKeys(d *extendedKeyDecoder) []string {
+	names := []string{}
+	for _, ev := range d.replay {
+		names = append(names, ev.Name())
+	}
+	d.replay = nil
+	return names
+}
+
+func TestExtendedKeyDecoderInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		supported bool
+		sequence  string
+		expected  []string
+		// the keys after those given back, which are handled as usual
+		passed []GocuiEvent
+	}{
+		{
+			name:     "before the terminal answered the query",
+			sequence: "\x1b[97u",
+			expected: []string{"Alt+Rune[[]", "Rune[9]"},
+			passed:   []GocuiEvent{{Type: eventKey, Ch: '7'}, {Type: eventKey, Ch: 'u'}},
+		},
+		{
+			name:     "answer to another query",
+			sequence: "\x1b[?1;2c",
+			expected: []string{"Alt+Rune[[]", "Rune[?]", "Rune[1]", "Rune[;]", "Rune[2]", "Rune[c]"},
+		},
+		{
+			name:      "unknown final character",
+			supported: true,
+			sequence:  "\x1b[1;2X",
+			expected:  []string{"Alt+Rune[[]", "Rune[1]", "Rune[;]", "Rune[2]", "Rune[X]"},
+		},
+		{
+			name:      "unknown parameters",
+			supported: true,
+			sequence:  "\x1b[<1u",
+			expected:  []string{"Alt+Rune[[]", "Rune[<]", "Rune[1]", "Rune[u]"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := &extendedKeyDecoder{}
+			if test.supported {
+				d.supported = 1
+			}
+			events := feedSequence(d, test.sequence)
+			assert.EqualValues(t, test.expected, replayedKeys(d))
+			if test.passed == nil {
+				test.passed = []GocuiEvent{}
+			}
+			assert.EqualValues(t, test.passed, events)
+		})
+	}
+}
+
+func TestExtendedKeyDecoderTimeout(t *testing.T) {
+	d := &extendedKeyDecoder{supported: 1}
+	_, ok := d.event(tcell.NewEventKey(tcell.KeyRune, '[', tcell.ModAlt))
+	assert.True(t, ok)
+	timeout := tcell.NewEventInterrupt(extendedKeyTimeout{id: d.id})
+
+	// the timeout of an earlier sequence is ignored
+	_, ok = d.event(tcell.NewEventInterrupt(extendedKeyTimeout{id: d.id - 1}))
+	assert.True(t, ok)
+	assert.Empty(t, d.replay)
+
+	_, ok = d.event(timeout)
+	assert.True(t, ok)
+	assert.EqualValues(t, []string{"Alt+Rune[[]"}, replayedKeys(d))
+	_, ok = d.event(tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone))
+	assert.False(t, ok)
+
+	// other interrupts are handled as usual
+	_, ok = d.event(tcell.NewEventInterrupt(nil))
+	assert.False(t, ok)
+}
+
+func TestPollExtendedKeysReplay(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 40, Height: 20, ExtendedKeys: true})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	// Alt+'[' followed by a key is reported as typed, before and after the
+	// terminal answered the query
+	for _, supported := range []int32{0, 1} {
+		g.extendedKeys.supported = supported
+		screen := Screen.(tcell.SimulationScreen)
+		screen.InjectKey(tcell.KeyRune, '[', tcell.ModAlt)
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+		ev := g.pollEvent()
+		assert.Equal(t, GocuiEvent{Type: eventKey, Ch: '[', Mod: ModAlt}, ev)
+		ev = g.pollEvent()
+		assert.Equal(t, GocuiEvent{Type: eventKey, Key: KeyEnter}, ev)
+	}
+
+	// Alt+'[' on its own is reported after a short while
+	Screen.(tcell.SimulationScreen).InjectKey(tcell.KeyRune, '[', tcell.ModAlt)
+	ev := g.pollEvent()
+	assert.Equal(t, GocuiEvent{Type: eventKey, Ch: '[', Mod: ModAlt}, ev)
+}
+
+func TestExtendedKeyBindings(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 80, Height: 24})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	fired := []string{}
+	bind := func(keys string) {
+		key, mod, err := Parse(keys)
+		assert.NoError(t, err)
+		assert.NoError(t, g.SetKeybinding("", key, mod, func(*Gui, *View) error {
+			fired = append(fired, keys)
+			return nil
+		}))
+	}
+	bind("Ctrl+Enter")
+	bind("Ctrl+Shift+K")
+	bind("Shift+Up")
+	bind("Alt+Enter")
+
+	press := func(key Key, ch rune, mod Modifier) {
+		assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Key: key, Ch: ch, Mod: mod}))
+	}
+	press(KeyEnter, 0, ModCtrl)
+	press(KeyCtrlK, 0, ModShift)
+	press(KeyCtrlK, 0, ModNone)
+	// keybindings of keys reported as placeholders without extended keys
+	// match the extended key presses too
+	press(KeyArrowUp, 0, ModShift)
+	press(KeyShiftArrowUp, 0, ModNone)
+	press(KeyEnter, 0, ModAlt)
+	assert.EqualValues(t, []string{"Ctrl+Enter", "Ctrl+Shift+K", "Shift+Up", "Shift+Up", "Alt+Enter"}, fired)
+}
+
+func TestKeyEventBindings(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 80, Height: 24})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	v, err := g.SetView("list", 0, 0, 10, 10, 0)
+	if !IsUnknownView(err) {
+		assert.NoError(t, err)
+	}
+	_, err = g.SetCurrentView("list")
+	assert.NoError(t, err)
+
+	fired := []string{}
+	bind := func(keys string) {
+		key, mod, err := Parse(keys)
+		assert.NoError(t, err)
+		assert.NoError(t, g.SetKeybinding("list", key, mod, func(*Gui, *View) error {
+			fired = append(fired, keys)
+			return nil
+		}))
+	}
+	bind("Down")
+	bind("Space")
+	bind("Repeat+Space")
+	bind("Release+Space")
+
+	press := func(key Key, keyEvent KeyEventType) {
+		assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Key: key, KeyEvent: keyEvent}))
+	}
+	// without keybindings for the repeat, the regular keybinding fires
+	press(KeyArrowDown, KeyEventPress)
+	press(KeyArrowDown, KeyEventRepeat)
+	press(KeyArrowDown, KeyEventRelease)
+	assert.EqualValues(t, []string{"Down", "Down"}, fired)
+
+	fired = []string{}
+	press(KeySpace, KeyEventPress)
+	press(KeySpace, KeyEventRepeat)
+	press(KeySpace, KeyEventRelease)
+	assert.EqualValues(t, []string{"Space", "Repeat+Space", "Release+Space"}, fired)
+
+	// editors don't see releases
+	v.Editable = true
+	edits := 0
+	v.Editor = EditorFunc(func(v *View, key Key, ch rune, mod Modifier) bool {
+		edits++
+		return true
+	})
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Ch: 'x', KeyEvent: KeyEventRelease}))
+	assert.Equal(t, 0, edits)
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Ch: 'x', KeyEvent: KeyEventRepeat}))
+	assert.Equal(t, 1, edits)
+}
+
+func TestPollKeyModifiers(t *testing.T) {
+	type expected struct {
+		key Key
+		ch  rune
+		mod Modifier
+	}
+	tests := []struct {
+		name     string
+		key      tcell.Key
+		ch       rune
+		mod      tcell.ModMask
+		legacy   expected
+		extended expected
+	}{
+		{
+			name:     "Ctrl+Up",
+			key:      tcell.KeyUp,
+			mod:      tcell.ModCtrl,
+			legacy:   expected{key: KeyArrowUp},
+			extended: expected{key: KeyArrowUp, mod: ModCtrl},
+		},
+		{
+			name:     "Shift+F5",
+			key:      tcell.KeyF5,
+			mod:      tcell.ModShift,
+			legacy:   expected{key: KeyF5},
+			extended: expected{key: KeyF5, mod: ModShift},
+		},
+		{
+			name:     "Ctrl+A",
+			key:      tcell.KeyCtrlA,
+			ch:       1,
+			mod:      tcell.ModCtrl,
+			legacy:   expected{key: KeyCtrlA},
+			extended: expected{key: KeyCtrlA},
+		},
+		{
+			name:     "Alt+Ctrl+Down",
+			key:      tcell.KeyDown,
+			mod:      tcell.ModCtrl | tcell.ModAlt,
+			legacy:   expected{key: KeyArrowDown, mod: ModCtrl | ModAlt},
+			extended: expected{key: KeyArrowDown, mod: ModCtrl | ModAlt},
+		},
+		{
+			name:     "Shift+Up",
+			key:      tcell.KeyUp,
+			mod:      tcell.ModShift,
+			legacy:   expected{key: KeyShiftArrowUp},
+			extended: expected{key: KeyArrowUp, mod: ModShift},
+		},
+		{
+			name:     "Alt+Enter",
+			key:      tcell.KeyEnter,
+			mod:      tcell.ModAlt,
+			legacy:   expected{key: KeyAltEnter},
+			extended: expected{key: KeyEnter, mod: ModAlt},
+		},
+	}
+
+	for _, extendedKeys := range []bool{false, true} {
+		g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 40, Height: 20, ExtendedKeys: extendedKeys})
+		assert.NoError(t, err)
+
+		// keybindings of placeholder keys match either way
+		fired := []string{}
+		for _, name := range []string{"Shift+Up", "Alt+Enter"} {
+			name := name
+			key, mod, err := Parse(name)
+			assert.NoError(t, err)
+			assert.NoError(t, g.SetKeybinding("", key, mod, func(*Gui, *View) error {
+				fired = append(fired, name)
+				return nil
+			}))
+		}
+
+		for _, test := range tests {
+			Screen.(tcell.SimulationScreen).InjectKey(test.key, test.ch, test.mod)
+			ev := g.pollEvent()
+			want := test.legacy
+			if extendedKeys {
+				want = test.extended
+			}
+			assert.Equal(t, want, expected{key: ev.Key, ch: ev.Ch, mod: ev.Mod}, "%s, extended keys: %v", test.name, extendedKeys)
+			assert.NoError(t, g.onKey(&ev))
+		}
+		assert.EqualValues(t, []string{"Shift+Up", "Alt+Enter"}, fired, "extended keys: %v", extendedKeys)
+
+		g.Close()
+	}
+}
+
+func TestPollShiftSelectKeys(t *testing.T) {
+	for _, shiftSelectKeys := range []bool{false, true} {
+		g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 40, Height: 20, ShiftSelectKeys: shiftSelectKeys})
+		assert.NoError(t, err)
+
+		fired := 0
+		assert.NoError(t, g.SetKeybinding("", KeyArrowLeft, ModNone, func(*Gui, *View) error {
+			fired++
+			return nil
+		}))
+
+		Screen.(tcell.SimulationScreen).InjectKey(tcell.KeyLeft, 0, tcell.ModShift)
+		ev := g.pollEvent()
+		assert.NoError(t, g.onKey(&ev))
+		if shiftSelectKeys {
+			assert.Equal(t, KeyShiftArrowLeft, ev.Key)
+			assert.Equal(t, 0, fired)
+		} else {
+			// Shift is dropped, like in other keys
+			assert.Equal(t, KeyArrowLeft, ev.Key)
+			assert.Equal(t, 1, fired)
+		}
+
+		// Shift+Up is always remapped
+		Screen.(tcell.SimulationScreen).InjectKey(tcell.KeyUp, 0, tcell.ModShift)
+		assert.Equal(t, KeyShiftArrowUp, g.pollEvent().Key)
+
+		g.Close()
+	}
+}
@@ -0,0 +1,136 @@
+package gocui
+
+import (
+	"github.com/go-errors/errors"
+)
+
+// maximum number of views remembered in the focus history
+const focusHistoryLimit = 50
+
+// focus gives the focus to v, which may be nil, calling the OnBlur callback
+// of the view losing it and the OnFocus callback of v. While a modal is open,
+// the focus stays on it.
+func (g *Gui) focus(v *View) error {
+	if m := g.Modal(); m != nil {
+		v = m.view
+	}
+
+	previous := g.currentView
+	if previous == v {
+		return nil
+	}
+
+	g.currentView = v
+	if previous != nil {
+		g.pushFocusHistory(previous)
+		if previous.OnBlur != nil {
+			if err := previous.OnBlur(previous); err != nil {
+				return err
+			}
+		}
+	}
+	if v != nil && v.OnFocus != nil {
+		return v.OnFocus(v)
+	}
+
+	return nil
+}
+
+// pushFocusHistory remembers that v had the focus, most recently.
+func (g *Gui) pushFocusHistory(v *View) {
+	g.removeFromFocusHistory(v)
+	g.focusHistory = append(g.focusHistory, v)
+	if len(g.focusHistory) > focusHistoryLimit {
+		g.focusHistory = g.focusHistory[len(g.focusHistory)-focusHistoryLimit:]
+	}
+}
+
+func (g *Gui) removeFromFocusHistory(v *View) {
+	history := g.focusHistory[:0]
+	for _, previous := range g.focusHistory {
+		if previous != v {
+			history = append(history, previous)
+		}
+	}
+	g.focusHistory = history
+}
+
+// previouslyFocused returns the view that most recently had the focus before
+// the current one, skipping those that were deleted since.
+func (g *Gui) previouslyFocused() *View {
+	for i := len(g.focusHistory) - 1; i >= 0; i-- {
+		v := g.focusHistory[i]
+		if existing, err := g.View(v.name); err == nil && existing == v {
+			return v
+		}
+	}
+
+	return nil
+}
+
+// SetTabOrder sets the names of the views FocusNext and FocusPrev cycle
+// through. By default, they cycle through all the visible views, from bottom
+// to top, other than those gocui creates itself. While a modal is open, it
+// keeps the focus.
+func (g *Gui) SetTabOrder(names ...string) {
+	g.tabOrder = names
+}
+
+// FocusNext gives the focus to the visible view following the current one in
+// the tab order, wrapping around at the end.
+func (g *Gui) FocusNext() (*View, error) {
+	return g.cycleFocus(1)
+}
+
+// FocusPrev gives the focus to the visible view preceding the current one in
+// the tab order, wrapping around at the start.
+func (g *Gui) FocusPrev() (*View, error) {
+	return g.cycleFocus(-1)
+}
+
+// internalViewNames are the names of the views gocui creates itself, which
+// FocusNext and FocusPrev skip.
+var internalViewNames = map[string]bool{
+	CompletionViewName: true,
+	HelpViewName:       true,
+	DragViewName:       true,
+}
+
+func (g *Gui) cycleFocus(step int) (*View, error) {
+	if m := g.Modal(); m != nil {
+		// the modal keeps the focus
+		return m.view, g.focus(m.view)
+	}
+
+	order := []*View{}
+	if len(g.tabOrder) > 0 {
+		for _, name := range g.tabOrder {
+			if v, err := g.View(name); err == nil && v.Visible {
+				order = append(order, v)
+			}
+		}
+	} else {
+		for _, v := range g.Views() {
+			if v.Visible && !internalViewNames[v.name] {
+				order = append(order, v)
+			}
+		}
+	}
+	if len(order) == 0 {
+		return nil, errors.Wrap(ErrUnknownView, 0)
+	}
+
+	next := 0
+	if step < 0 {
+		next = len(order) - 1
+	}
+	for i, v := range order {
+		if v == g.currentView {
+			next = (i + step + len(order)) % len(order)
+			break
+		}
+	}
+
+	v := order[next]
+	return v, g.focus(v)
+}
@@ -0,0 +1,98 @@
+package gocui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFocus(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 40, Height: 20})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	events := []string{}
+	for _, name := range []string{"a", "b", "c"} {
+		v, _ := g.SetView(name, 0, 0, 10, 10, 0)
+		v.OnFocus = func(v *View) error {
+			events = append(events, "focus "+v.Name())
+			return nil
+		}
+		v.OnBlur = func(v *View) error {
+			events = append(events, "blur "+v.Name())
+			return nil
+		}
+	}
+
+	_, err = g.SetCurrentView("a")
+	assert.NoError(t, err)
+	_, err = g.SetCurrentView("b")
+	assert.NoError(t, err)
+	// focusing the current view again does nothing
+	_, err = g.SetCurrentView("b")
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"focus a", "blur a", "focus b"}, events)
+
+	// deleting the current view gives the focus back to the previous one
+	events = nil
+	_, err = g.SetCurrentView("c")
+	assert.NoError(t, err)
+	assert.NoError(t, g.DeleteView("c"))
+	assert.EqualValues(t, "b", g.CurrentView().Name())
+	assert.NoError(t, g.DeleteView("b"))
+	assert.EqualValues(t, "a", g.CurrentView().Name())
+	assert.EqualValues(t, []string{"blur b", "focus c", "blur c", "focus b", "blur b", "focus a"}, events)
+	assert.NoError(t, g.DeleteView("a"))
+	assert.Nil(t, g.CurrentView())
+}
+
+func TestFocusNext(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 40, Height: 20})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	for _, name := range []string{"a", "b", "c", "d"} {
+		_, _ = g.SetView(name, 0, 0, 10, 10, 0)
+	}
+	hidden, _ := g.View("d")
+	hidden.Visible = false
+
+	focusNext := func(expected string) {
+		v, err := g.FocusNext()
+		assert.NoError(t, err)
+		assert.EqualValues(t, expected, v.Name())
+		assert.EqualValues(t, expected, g.CurrentView().Name())
+	}
+	focusPrev := func(expected string) {
+		v, err := g.FocusPrev()
+		assert.NoError(t, err)
+		assert.EqualValues(t, expected, v.Name())
+	}
+
+	// hidden views are skipped
+	focusNext("a")
+	focusNext("b")
+	focusNext("c")
+	focusNext("a")
+	focusPrev("c")
+
+	// internal views are skipped
+	_, _ = g.SetView(CompletionViewName, 0, 0, 10, 10, 0)
+	_, _ = g.SetView(DragViewName, 0, 0, 10, 10, 0)
+	focusNext("a")
+	focusNext("b")
+	focusNext("c")
+
+	g.SetTabOrder("c", "d", "a")
+	focusNext("a")
+	focusNext("c")
+	focusPrev("a")
+
+	// a modal keeps the focus
+	_, err = g.PushModal(&Modal{Name: "confirm", Width: 20, Height: 6})
+	assert.NoError(t, err)
+	focusNext("confirm")
+	focusPrev("confirm")
+	assert.NoError(t, g.PopModal())
+	assert.EqualValues(t, "a", g.CurrentView().Name())
+}
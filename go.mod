@@ -8,6 +8,7 @@ require (
 	github.com/go-errors/errors v1.0.2
 	github.com/lucasb-eyer/go-colorful v1.2.0
 	github.com/mattn/go-runewidth v0.0.16
-	github.com/rivo/uniseg v0.4.7 // indirect
+	github.com/rivo/uniseg v0.4.7
 	github.com/stretchr/testify v1.7.0
+	golang.org/x/text v0.21.0
 )
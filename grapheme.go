@@ -0,0 +1,94 @@
+package gocui
+
+import (
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
+)
+
+// grapheme is a user-perceived character, which may consist of several runes,
+// e.g. a letter followed by combining accents, a flag, or an emoji joined with
+// ZWJ.
+type grapheme struct {
+	// the runes of the grapheme cluster
+	runes []rune
+	// the number of cells it takes up on the screen
+	width int
+}
+
+// graphemes splits runes into grapheme clusters.
+func graphemes(runes []rune) []grapheme {
+	result := make([]grapheme, 0, len(runes))
+	str := string(runes)
+	state := -1
+	pos := 0
+	for len(str) > 0 {
+		var cluster string
+		cluster, str, _, state = uniseg.FirstGraphemeClusterInString(str, state)
+		n := utf8.RuneCountInString(cluster)
+		result = append(result, grapheme{runes: runes[pos : pos+n], width: clusterWidth(runes[pos : pos+n])})
+		pos += n
+	}
+
+	return result
+}
+
+// nextGraphemeBoundary returns the end of the grapheme cluster starting at
+// pos.
+func nextGraphemeBoundary(runes []rune, pos int) int {
+	if pos >= len(runes) {
+		return len(runes)
+	}
+
+	// clusters don't extend past a line break, so there's no need to look
+	// further than that
+	end := pos
+	for end < len(runes) && runes[end] != '\n' {
+		end++
+	}
+	end = min(end+1, len(runes))
+
+	cluster, _, _, _ := uniseg.FirstGraphemeClusterInString(string(runes[pos:end]), -1)
+	return pos + utf8.RuneCountInString(cluster)
+}
+
+// prevGraphemeBoundary returns the start of the grapheme cluster ending at pos.
+func prevGraphemeBoundary(runes []rune, pos int) int {
+	if pos <= 0 {
+		return 0
+	}
+
+	// clusters don't extend past a line break, so we start looking at the
+	// start of the line
+	start := pos - 1
+	for start > 0 && runes[start-1] != '\n' {
+		start--
+	}
+
+	boundary := start
+	for next := start; next < pos; next = nextGraphemeBoundary(runes, next) {
+		boundary = next
+	}
+
+	return boundary
+}
+
+// clusterWidth returns the number of cells a grapheme cluster takes up on the
+// screen. Single runes are measured with go-runewidth like everywhere else,
+// so that plain text is laid out the same as always.
+func clusterWidth(cluster []rune) int {
+	if len(cluster) == 1 {
+		return runewidth.RuneWidth(cluster[0])
+	}
+
+	return uniseg.StringWidth(string(cluster))
+}
+
+// continuesGrapheme reports whether r belongs to the same grapheme cluster as
+// the runes before it, e.g. because it's a combining accent.
+func continuesGrapheme(cluster []rune, r rune) bool {
+	str := string(cluster) + string(r)
+	first, _, _, _ := uniseg.FirstGraphemeClusterInString(str, -1)
+	return len(first) == len(str)
+}
@@ -31,6 +31,9 @@ var (
 	// ErrNotBlacklisted is returned when a keybinding being whitelisted is not blacklisted.
 	ErrNotBlacklisted = standardErrors.New("keybind not blacklisted")
 
+	// ErrInvalidKeySequence is returned when a sequence keybinding has no keys.
+	ErrInvalidKeySequence = standardErrors.New("invalid key sequence")
+
 	// ErrNoSuchKeybind is returned when the keybinding being parsed does not exist.
 	ErrNoSuchKeybind = standardErrors.New("no such keybind")
 
@@ -87,11 +90,31 @@ type ViewMouseBinding struct {
 
 	// must be a mouse key
 	Key Key
+
+	// Event is what must happen with the mouse key: a press by default, or
+	// e.g. the start of a drag. Drag and release bindings belong to the view
+	// the button was pressed in, wherever the pointer is. Move, enter and
+	// leave bindings ignore Key and Modifier.
+	Event MouseEventType
+
+	// ClickCount, if set, makes a press binding only match single (1),
+	// double (2) or triple (3) clicks. It's preferred over a binding of the
+	// same press without ClickCount.
+	ClickCount int
 }
 
 type ViewMouseBindingOpts struct {
 	X int // i.e. origin x + cursor x
 	Y int // i.e. origin y + cursor y
+
+	// ClickCount is 1, 2 or 3 for single, double and triple clicks.
+	ClickCount int
+
+	// OriginX and OriginY are where the button was pressed, relative to the
+	// content of the view like X and Y. They're X and Y for events without a
+	// button.
+	OriginX int
+	OriginY int
 }
 
 type GuiMutexes struct {
@@ -129,6 +152,9 @@ type Gui struct {
 	currentView       *View
 	managers          []Manager
 	keybindings       []*keybinding
+	contexts          []*KeybindingContext
+	sequenceBindings  []*sequenceBinding
+	pendingSequence   pendingSequence
 	focusHandler      func(bool) error
 	openHyperlink     func(string, string) error
 	maxX, maxY        int
@@ -167,6 +193,27 @@ type Gui struct {
 	// view edges
 	SupportOverlaps bool
 
+	// KeySequenceTimeout is how long to wait for the next key of a sequence
+	// keybinding before giving up on it. Zero means waiting forever.
+	KeySequenceTimeout time.Duration
+
+	// DoubleClickInterval is how long a click may follow the previous one
+	// at the same position to count as a double or triple click. It should
+	// be set before the main loop starts.
+	DoubleClickInterval time.Duration
+
+	// OnPendingKeySequence, if set, is called with the keys typed so far
+	// whenever they may be the start of a sequence keybinding, e.g. to show
+	// "g-" in a status bar, and with no keys when the sequence is done.
+	OnPendingKeySequence func([]KeyPress) error
+
+	// OnKeybindingConflict, if set, is called when a keybinding is registered
+	// for the same view, context and keys as an existing one, e.g. to log it.
+	OnKeybindingConflict func(*KeybindingConflictError)
+	// If StrictKeybindings is true, registering such a keybinding fails with
+	// a *KeybindingConflictError instead, which is useful in tests.
+	StrictKeybindings bool
+
 	Mutexes GuiMutexes
 
 	OnSearchEscape func() error
@@ -183,7 +230,41 @@ type Gui struct {
 
 	taskManager *TaskManager
 
+	clipboard Clipboard
+
+	// the completion popup, if open
+	completion *completion
+
+	// the open modals, the topmost last
+	modals []*Modal
+
+	// the views that had the focus before the current one, the most recent
+	// last
+	focusHistory []*View
+	tabOrder     []string
+
 	lastHoverView *View
+
+	// the view the mouse button was pressed in, and the view under the
+	// pointer
+	mouseDownView *View
+	mouseOverView *View
+	mouseState    mouseState
+
+	// the view with text selected with the mouse
+	selectionView *View
+
+	dragSources []*DragSource
+	dropTargets []*DropTarget
+	// the drag-and-drop in progress, if any
+	drag *drag
+
+	// decodes the key presses of the kitty keyboard protocol, if
+	// NewGuiOpts.ExtendedKeys is set
+	extendedKeys *extendedKeyDecoder
+
+	// see NewGuiOpts.ShiftSelectKeys
+	shiftSelectKeys bool
 }
 
 type NewGuiOpts struct {
@@ -197,6 +278,21 @@ type NewGuiOpts struct {
 	Height int
 
 	RuneReplacements map[rune]string
+
+	// ExtendedKeys enables the kitty keyboard protocol in terminals that
+	// support it. Key presses are then reported with all their modifiers,
+	// so that keys like Ctrl+Enter, Ctrl+Shift+A or Ctrl+1 can be bound.
+	// Keys with placeholders are reported as they are, e.g. Shift+Up as
+	// KeyArrowUp with ModShift rather than KeyShiftArrowUp, but keybindings
+	// of the placeholders still match them.
+	ExtendedKeys bool
+
+	// ShiftSelectKeys reports Shift+Left, Shift+Right, Shift+Home and
+	// Shift+End as KeyShiftArrowLeft, KeyShiftArrowRight, KeyShiftHome and
+	// KeyShiftEnd, so that they select text in editable views. Otherwise
+	// they're reported without Shift, like other keys. It has no effect with
+	// ExtendedKeys, where they're always reported with ModShift.
+	ShiftSelectKeys bool
 }
 
 // NewGui returns a new Gui object with a given output mode.
@@ -251,8 +347,22 @@ func NewGui(opts NewGuiOpts) (*Gui, error) {
 	g.NextSearchMatchKey = 'n'
 	g.PrevSearchMatchKey = 'N'
 
+	g.KeySequenceTimeout = time.Second
+	g.DoubleClickInterval = 500 * time.Millisecond
+
 	g.playRecording = opts.PlayRecording
 
+	if opts.ExtendedKeys {
+		g.extendedKeys = &extendedKeyDecoder{}
+	}
+	g.shiftSelectKeys = opts.ShiftSelectKeys
+
+	if opts.Headless {
+		g.clipboard = &MemoryClipboard{}
+	} else {
+		g.clipboard = NewOSC52Clipboard(g.screen)
+	}
+
 	return g, nil
 }
 
@@ -271,6 +381,9 @@ func (g *Gui) AddIdleListener(c chan struct{}) {
 // initialization and when gocui is not needed anymore.
 func (g *Gui) Close() {
 	close(g.stop)
+	if g.extendedKeys != nil {
+		writeExtendedKeys(extendedKeysPop)
+	}
 	Screen.Fini()
 }
 
@@ -287,7 +400,7 @@ func (g *Gui) SetRune(x, y int, ch rune, fgColor, bgColor Attribute) error {
 		// swallowing error because it's not that big of a deal
 		return nil
 	}
-	tcellSetCell(x, y, ch, fgColor, bgColor, g.outputMode)
+	tcellSetCell(x, y, ch, nil, fgColor, bgColor, g.outputMode)
 	return nil
 }
 
@@ -341,7 +454,9 @@ func (g *Gui) SetView(name string, x0, y0, x1, y1 int, overlaps byte) (*View, er
 	v.BgColor, v.FgColor = g.BgColor, g.FgColor
 	v.SelBgColor, v.SelFgColor = g.SelBgColor, g.SelFgColor
 	v.Overlaps = overlaps
+	v.TextArea.Clipboard = g.clipboard
 	g.views = append(g.views, v)
+	g.sortViews()
 
 	g.Mutexes.ViewsMutex.Unlock()
 
@@ -359,36 +474,47 @@ func (g *Gui) SetViewBeneath(name string, aboveViewName string, height int) (*Vi
 	return g.SetView(name, aboveView.x0, viewTop, aboveView.x1, viewTop+height-1, 0)
 }
 
-// SetViewOnTop sets the given view on top of the existing ones.
+// SetViewOnTop sets the given view on top of the existing ones of its layer.
 func (g *Gui) SetViewOnTop(name string) (*View, error) {
 	g.Mutexes.ViewsMutex.Lock()
 	defer g.Mutexes.ViewsMutex.Unlock()
 
 	for i, v := range g.views {
 		if v.name == name {
+			if _, highest, found := g.zIndexBounds(v.Layer, v); found {
+				v.ZIndex = max(v.ZIndex, highest)
+			}
 			s := append(g.views[:i], g.views[i+1:]...)
 			g.views = append(s, v)
+			g.sortViews()
 			return v, nil
 		}
 	}
 	return nil, errors.Wrap(ErrUnknownView, 0)
 }
 
-// SetViewOnBottom sets the given view on bottom of the existing ones.
+// SetViewOnBottom sets the given view on bottom of the existing ones of its
+// layer.
 func (g *Gui) SetViewOnBottom(name string) (*View, error) {
 	g.Mutexes.ViewsMutex.Lock()
 	defer g.Mutexes.ViewsMutex.Unlock()
 
 	for i, v := range g.views {
 		if v.name == name {
+			if lowest, _, found := g.zIndexBounds(v.Layer, v); found {
+				v.ZIndex = min(v.ZIndex, lowest)
+			}
 			s := append(g.views[:i], g.views[i+1:]...)
 			g.views = append([]*View{v}, s...)
+			g.sortViews()
 			return v, nil
 		}
 	}
 	return nil, errors.Wrap(ErrUnknownView, 0)
 }
 
+// SetViewOnTopOf sets the view toMove right on top of the view other. toMove
+// takes the layer and z-index of other.
 func (g *Gui) SetViewOnTopOf(toMove string, other string) error {
 	g.Mutexes.ViewsMutex.Lock()
 	defer g.Mutexes.ViewsMutex.Unlock()
@@ -415,16 +541,22 @@ func (g *Gui) SetViewOnTopOf(toMove string, other string) error {
 		return errors.Wrap(ErrUnknownView, 0)
 	}
 
+	viewToMove, otherView := g.views[toMoveIndex], g.views[otherIndex]
+
 	// already on top
-	if toMoveIndex > otherIndex {
+	if toMoveIndex > otherIndex && viewToMove.Layer == otherView.Layer && viewToMove.ZIndex == otherView.ZIndex {
 		return nil
 	}
 
-	// need to actually do it the other way around. Last is highest
-	viewToMove := g.views[toMoveIndex]
+	viewToMove.Layer, viewToMove.ZIndex = otherView.Layer, otherView.ZIndex
 
+	// need to actually do it the other way around. Last is highest
 	g.views = append(g.views[:toMoveIndex], g.views[toMoveIndex+1:]...)
-	g.views = append(g.views[:otherIndex], append([]*View{viewToMove}, g.views[otherIndex:]...)...)
+	if toMoveIndex < otherIndex {
+		otherIndex--
+	}
+	g.views = append(g.views[:otherIndex+1], append([]*View{viewToMove}, g.views[otherIndex+1:]...)...)
+	g.sortViews()
 	return nil
 }
 
@@ -462,10 +594,13 @@ func (g *Gui) VisibleViewByPosition(x, y int) (*View, error) {
 	defer g.Mutexes.ViewsMutex.Unlock()
 
 	// traverse views in reverse order checking top views first
+	g.sortViews()
 	for i := len(g.views); i > 0; i-- {
 		v := g.views[i-1]
 
-		if !v.Visible {
+		// the label of a drag-and-drop follows the pointer, and mustn't hide
+		// the views beneath it
+		if !v.Visible || v.name == DragViewName {
 			continue
 		}
 
@@ -494,32 +629,58 @@ func (g *Gui) ViewPosition(name string) (x0, y0, x1, y1 int, err error) {
 	return 0, 0, 0, 0, errors.Wrap(ErrUnknownView, 0)
 }
 
-// DeleteView deletes a view by name.
+// DeleteView deletes a view by name. If the view has the focus, the focus
+// goes back to the view that had it before.
 func (g *Gui) DeleteView(name string) error {
 	g.Mutexes.ViewsMutex.Lock()
-	defer g.Mutexes.ViewsMutex.Unlock()
 
+	var deleted *View
 	for i, v := range g.views {
 		if v.name == name {
 			g.views = append(g.views[:i], g.views[i+1:]...)
-			return nil
+			deleted = v
+			break
 		}
 	}
-	return errors.Wrap(ErrUnknownView, 0)
+
+	g.Mutexes.ViewsMutex.Unlock()
+
+	if deleted == nil {
+		return errors.Wrap(ErrUnknownView, 0)
+	}
+
+	if g.mouseDownView == deleted {
+		g.mouseDownView = nil
+	}
+	if g.mouseOverView == deleted {
+		g.mouseOverView = nil
+	}
+	if g.selectionView == deleted {
+		g.selectionView = nil
+	}
+	g.removeModal(deleted)
+	g.DeleteDragSource(name)
+	g.DeleteDropTarget(name)
+
+	g.removeFromFocusHistory(deleted)
+	if g.currentView == deleted {
+		err := g.focus(g.previouslyFocused())
+		g.removeFromFocusHistory(deleted)
+		return err
+	}
+	return nil
 }
 
-// SetCurrentView gives the focus to a given view.
+// SetCurrentView gives the focus to a given view, calling the OnBlur callback
+// of the view losing it and its own OnFocus callback. While a modal is open,
+// the focus stays on the modal.
 func (g *Gui) SetCurrentView(name string) (*View, error) {
-	g.Mutexes.ViewsMutex.Lock()
-	defer g.Mutexes.ViewsMutex.Unlock()
-
-	for _, v := range g.views {
-		if v.name == name {
-			g.currentView = v
-			return v, nil
-		}
+	v, err := g.View(name)
+	if err != nil {
+		return nil, err
 	}
-	return nil, errors.Wrap(ErrUnknownView, 0)
+
+	return v, g.focus(v)
 }
 
 // CurrentView returns the currently focused view, or nil if no view
@@ -549,8 +710,7 @@ func (g *Gui) SetKeybinding(viewname string, key interface{}, mod Modifier, hand
 	}
 
 	kb = newKeybinding(viewname, k, ch, mod, handler)
-	g.keybindings = append(g.keybindings, kb)
-	return nil
+	return g.addKeybinding(kb)
 }
 
 // DeleteKeybinding deletes a keybinding.
@@ -561,7 +721,7 @@ func (g *Gui) DeleteKeybinding(viewname string, key interface{}, mod Modifier) e
 	}
 
 	for i, kb := range g.keybindings {
-		if kb.viewName == viewname && kb.ch == ch && kb.key == k && kb.mod == mod {
+		if kb.context == "" && kb.viewName == viewname && kb.ch == ch && kb.key == k && kb.mod == mod {
 			g.keybindings = append(g.keybindings[:i], g.keybindings[i+1:]...)
 			return nil
 		}
@@ -574,6 +734,10 @@ func (g *Gui) DeleteAllKeybindings() {
 	g.keybindings = []*keybinding{}
 	g.tabClickBindings = []*tabClickBinding{}
 	g.viewMouseBindings = []*ViewMouseBinding{}
+	g.dragSources = []*DragSource{}
+	g.dropTargets = []*DropTarget{}
+	g.sequenceBindings = []*sequenceBinding{}
+	g.dropPendingSequence()
 }
 
 // DeleteKeybindings deletes all keybindings of view.
@@ -585,6 +749,7 @@ func (g *Gui) DeleteViewKeybindings(viewname string) {
 		}
 	}
 	g.keybindings = s
+	g.DeleteSequenceKeybindings(viewname)
 }
 
 // SetTabClickBinding sets a binding for a tab click event
@@ -718,6 +883,12 @@ type Manager interface {
 	Layout(*Gui) error
 }
 
+// mouseManager is implemented by managers that handle mouse events before
+// the views do, like Layout for dragging the dividers of splits.
+type mouseManager interface {
+	onMouse(g *Gui, ev *GocuiEvent) (bool, error)
+}
+
 // The ManagerFunc type is an adapter to allow the use of ordinary functions as
 // Managers. If f is a function with the appropriate signature, ManagerFunc(f)
 // is an Manager object that calls f.
@@ -733,9 +904,20 @@ func (f ManagerFunc) Layout(g *Gui) error {
 func (g *Gui) SetManager(managers ...Manager) {
 	g.managers = managers
 	g.currentView = nil
+	g.focusHistory = nil
 	g.views = nil
 	g.keybindings = nil
 	g.tabClickBindings = nil
+	g.sequenceBindings = nil
+	g.dropPendingSequence()
+	g.mouseDownView = nil
+	g.mouseOverView = nil
+	g.selectionView = nil
+	g.dragSources = nil
+	g.dropTargets = nil
+	g.drag = nil
+	g.modals = nil
+	g.completion = nil
 
 	go func() { g.gEvents <- GocuiEvent{Type: eventResize} }()
 }
@@ -762,6 +944,9 @@ func (g *Gui) MainLoop() error {
 
 	Screen.EnableFocus()
 	Screen.EnablePaste()
+	if g.extendedKeys != nil {
+		writeExtendedKeys(extendedKeysQuery + extendedKeysPush)
+	}
 
 	previousEnableMouse := false
 	for {
@@ -1167,6 +1352,26 @@ func (g *Gui) drawListFooter(v *View, fgColor, bgColor Attribute) error {
 	return nil
 }
 
+// drawHistorySearch draws the indicator of a history search in progress on the
+// bottom edge of the view's frame.
+func (g *Gui) drawHistorySearch(v *View, fgColor, bgColor Attribute) error {
+	if v.y1 < 0 || v.y1 >= g.maxY {
+		return nil
+	}
+
+	x := v.x0 + 1
+	for _, ch := range v.History.SearchIndicator() {
+		if x >= v.x1 {
+			break
+		}
+		if err := g.SetRune(x, v.y1, ch, fgColor, bgColor); err != nil {
+			return err
+		}
+		x += runewidth.RuneWidth(ch)
+	}
+	return nil
+}
+
 // flush updates the gui, re-drawing frames and buffers.
 func (g *Gui) flush() error {
 	// pretty sure we don't need this, but keeping it here in case we get weird visual artifacts
@@ -1186,7 +1391,20 @@ func (g *Gui) flush() error {
 			return err
 		}
 	}
+	if err := g.layoutModals(); err != nil {
+		return err
+	}
+
+	// the layers and z-indices may have been changed directly
+	g.Mutexes.ViewsMutex.Lock()
+	g.sortViews()
+	g.Mutexes.ViewsMutex.Unlock()
+
+	dimmedBeneath := g.dimmedBeneath()
 	for _, v := range g.views {
+		if v == dimmedBeneath {
+			g.dimScreen()
+		}
 		if err := g.draw(v); err != nil {
 			return err
 		}
@@ -1242,7 +1460,7 @@ func (g *Gui) draw(v *View) error {
 			}
 
 			gMaxX, gMaxY := g.Size()
-			cx, cy := curview.x0+curview.cx+1, curview.y0+curview.cy+1
+			cx, cy := curview.x0+curview.visualCursorX()+1, curview.y0+curview.cy+1
 			// This test probably doesn't need to be here.
 			// tcell is hiding cursor by setting coordinates outside of screen.
 			// Keeping it here for now, as I'm not 100% sure :)
@@ -1299,6 +1517,11 @@ func (g *Gui) draw(v *View) error {
 				return err
 			}
 		}
+		if v.History != nil && v.History.Searching() {
+			if err := g.drawHistorySearch(v, fgColor, bgColor); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -1325,16 +1548,45 @@ func (g *Gui) onKey(ev *GocuiEvent) error {
 			ev.Key = KeyCtrlM
 		}
 
+		if ev.KeyEvent == KeyEventRelease {
+			// releases only fire the keybindings with ModRelease
+			return g.execKeybindings(g.currentView, ev)
+		}
+
+		if handled, err := g.onCompletionKey(ev); handled {
+			return err
+		}
+
+		if handled, err := g.onSequenceKey(ev); handled {
+			return err
+		}
+
 		err := g.execKeybindings(g.currentView, ev)
 		if err != nil {
 			return err
 		}
 
+		if err := g.updateCompletion(); err != nil {
+			return err
+		}
+
 	case eventMouse:
+		if g.Modal() == nil {
+			if handled, err := g.execMouseManagers(ev); handled {
+				return err
+			}
+		}
+
 		mx, my := ev.MouseX, ev.MouseY
 		v, err := g.VisibleViewByPosition(mx, my)
-		if err != nil {
-			break
+		if err != nil || g.modalBlocks(v) {
+			v = nil
+		}
+		if err := g.setMouseOverView(v, ev); err != nil {
+			return err
+		}
+		if handled, err := g.onMouseButton(ev); handled || err != nil || v == nil {
+			return err
 		}
 		if v.Frame && my == v.y0 {
 			if len(v.Tabs) > 0 {
@@ -1351,11 +1603,25 @@ func (g *Gui) onKey(ev *GocuiEvent) error {
 		}
 
 		// newCx and newCy are relative to the view port, i.e. to the visible area of the view
-		newCx := mx - v.x0 - 1
 		newCy := my - v.y0 - 1
+		newCx := v.logicalX(mx-v.x0-1, newCy)
 		// newX and newY are relative to the view's content, independent of its scroll position
 		newX := newCx + v.ox
 		newY := newCy + v.oy
+		if g.completion != nil {
+			if v.name == CompletionViewName {
+				if ev.Key == MouseLeft && newY >= 0 && newY < len(g.completion.candidates) {
+					g.completion.selected = newY
+					return g.AcceptCompletion()
+				}
+				break
+			}
+			if !IsMouseScrollKey(ev.Key) {
+				if err := g.CloseCompletion(); err != nil {
+					return err
+				}
+			}
+		}
 		// if view is editable don't go further than the furthest character for that line
 		if v.Editable {
 			if newY < 0 {
@@ -1378,6 +1644,13 @@ func (g *Gui) onKey(ev *GocuiEvent) error {
 		if !IsMouseScrollKey(ev.Key) {
 			v.SetCursor(newCx, newCy)
 			if v.Editable {
+				// dragging with the left button selects text; any other click
+				// drops the selection
+				if ev.Key == MouseLeft && ev.Mod&ModMotion != 0 {
+					v.TextArea.StartSelection()
+				} else {
+					v.TextArea.ClearSelection()
+				}
 				v.TextArea.SetCursor2D(newX, newY)
 
 				// SetCursor2D might have adjusted the text area's cursor to the
@@ -1397,8 +1670,9 @@ func (g *Gui) onKey(ev *GocuiEvent) error {
 		}
 
 		if IsMouseKey(ev.Key) {
-			opts := ViewMouseBindingOpts{X: newX, Y: newY}
-			matched, err := g.execMouseKeybindings(v, ev, opts)
+			opts := v.mouseBindingOpts(ev)
+			opts.X, opts.Y = newX, newY
+			matched, err := g.execMouseKeybindings(v, ev, MouseEventPress, opts)
 			if err != nil {
 				return err
 			}
@@ -1412,10 +1686,27 @@ func (g *Gui) onKey(ev *GocuiEvent) error {
 		}
 
 	case eventMouseMove:
+		if g.Modal() == nil {
+			if handled, err := g.execMouseManagers(ev); handled {
+				return err
+			}
+		}
+
 		mx, my := ev.MouseX, ev.MouseY
 		v, err := g.VisibleViewByPosition(mx, my)
-		if err != nil {
-			break
+		if err != nil || g.modalBlocks(v) {
+			v = nil
+		}
+		if err := g.setMouseOverView(v, ev); err != nil {
+			return err
+		}
+		if handled, err := g.onMouseButton(ev); handled || err != nil || v == nil {
+			return err
+		}
+		if ev.MouseEvent == MouseEventMove {
+			if _, err := g.execMouseKeybindings(v, ev, MouseEventMove, v.mouseBindingOpts(ev)); err != nil {
+				return err
+			}
 		}
 		if g.lastHoverView != nil && g.lastHoverView != v {
 			g.lastHoverView.lastHoverPosition = nil
@@ -1430,27 +1721,49 @@ func (g *Gui) onKey(ev *GocuiEvent) error {
 	return nil
 }
 
-func (g *Gui) execMouseKeybindings(view *View, ev *GocuiEvent, opts ViewMouseBindingOpts) (bool, error) {
-	isMatch := func(binding *ViewMouseBinding) bool {
-		return binding.ViewName == view.Name() &&
-			ev.Key == binding.Key &&
-			ev.Mod == binding.Modifier
-	}
-
-	// first pass looks for ones that match the focused view
-	for _, binding := range g.viewMouseBindings {
-		if isMatch(binding) && binding.FocusedView != "" && binding.FocusedView == g.currentView.Name() {
-			return true, binding.Handler(opts)
+// execMouseManagers passes a mouse event to the managers that want to handle
+// it. It returns true if one of them did.
+func (g *Gui) execMouseManagers(ev *GocuiEvent) (bool, error) {
+	for _, m := range g.managers {
+		if mm, ok := m.(mouseManager); ok {
+			if handled, err := mm.onMouse(g, ev); handled || err != nil {
+				return true, err
+			}
 		}
 	}
 
+	return false, nil
+}
+
+// execMouseKeybindings calls the mouse binding of the view for the event, of
+// the given type. Bindings for the focused view come first, then bindings
+// with a click count.
+func (g *Gui) execMouseKeybindings(view *View, ev *GocuiEvent, event MouseEventType, opts ViewMouseBindingOpts) (bool, error) {
+	var match *ViewMouseBinding
+	matchScore := -1
 	for _, binding := range g.viewMouseBindings {
-		if isMatch(binding) && binding.FocusedView == "" {
-			return true, binding.Handler(opts)
+		if binding.ViewName != view.Name() || binding.Event != event || !binding.matches(ev) {
+			continue
+		}
+		score := 0
+		if binding.FocusedView != "" {
+			if g.currentView == nil || binding.FocusedView != g.currentView.Name() {
+				continue
+			}
+			score += 2
+		}
+		if binding.ClickCount != 0 {
+			score++
+		}
+		if score > matchScore {
+			match, matchScore = binding, score
 		}
 	}
 
-	return false, nil
+	if match == nil {
+		return false, nil
+	}
+	return true, match.Handler(opts)
 }
 
 func IsMouseKey(key interface{}) bool {
@@ -1486,15 +1799,12 @@ func IsMouseScrollKey(key interface{}) bool {
 // execKeybindings executes the keybinding handlers that match the passed view
 // and event.
 func (g *Gui) execKeybindings(v *View, ev *GocuiEvent) error {
-	var globalKb *keybinding
-	var matchingParentViewKb *keybinding
-
 	if g.IsPasting && v != nil && !v.Editable {
 		return nil
 	}
 
 	// if we're searching, and we've hit n/N/Esc, we ignore the default keybinding
-	if v != nil && v.IsSearching() && ev.Mod == ModNone {
+	if v != nil && v.IsSearching() && ev.Mod == ModNone && ev.KeyEvent != KeyEventRelease {
 		if eventMatchesKey(ev, g.NextSearchMatchKey) {
 			return v.gotoNextMatch()
 		} else if eventMatchesKey(ev, g.PrevSearchMatchKey) {
@@ -1510,28 +1820,20 @@ func (g *Gui) execKeybindings(v *View, ev *GocuiEvent) error {
 		}
 	}
 
-	for _, kb := range g.keybindings {
-		if kb.handler == nil {
-			continue
-		}
-		if !kb.matchKeypress(ev.Key, ev.Ch, ev.Mod) {
-			continue
-		}
-		if g.matchView(v, kb) {
-			return g.execKeybinding(v, kb)
-		}
-		if v != nil && g.matchView(v.ParentView, kb) {
-			matchingParentViewKb = kb
-		}
-		if globalKb == nil && kb.viewName == "" && ((v != nil && !v.Editable) || (kb.ch == 0 && kb.key != KeyCtrlU && kb.key != KeyCtrlA && kb.key != KeyCtrlE)) {
-			globalKb = kb
-		}
+	matchingViewKb, matchingParentViewKb, globalKb := g.matchingKeybindings(v, ev.Key, ev.Ch, ev.Mod|ev.KeyEvent.modifier())
+	if ev.KeyEvent == KeyEventRepeat && matchingViewKb == nil && matchingParentViewKb == nil && globalKb == nil {
+		// without keybindings for the repeat, holding a key down repeats its
+		// regular keybindings, as in terminals that don't report repeats
+		matchingViewKb, matchingParentViewKb, globalKb = g.matchingKeybindings(v, ev.Key, ev.Ch, ev.Mod)
+	}
+	if matchingViewKb != nil {
+		return g.execKeybinding(v, matchingViewKb)
 	}
 	if matchingParentViewKb != nil {
 		return g.execKeybinding(v.ParentView, matchingParentViewKb)
 	}
 
-	if g.currentView != nil && g.currentView.Editable && g.currentView.Editor != nil {
+	if g.currentView != nil && g.currentView.Editable && g.currentView.Editor != nil && ev.KeyEvent != KeyEventRelease {
 		matched := g.currentView.Editor.Edit(g.currentView, ev.Key, ev.Ch, ev.Mod)
 		if matched {
 			return nil
@@ -1544,6 +1846,41 @@ func (g *Gui) execKeybindings(v *View, ev *GocuiEvent) error {
 	return nil
 }
 
+// matchingKeybindings returns the keybindings of the view, of its parent view
+// and the global one that match a key press. Among those, the ones of the
+// topmost active context win.
+func (g *Gui) matchingKeybindings(v *View, key Key, ch rune, mod Modifier) (viewKb *keybinding, parentViewKb *keybinding, globalKb *keybinding) {
+	var viewRank, parentViewRank, globalRank int
+
+	ranks, globalsUpTo := g.contextRanks()
+	for _, kb := range g.keybindings {
+		if kb.handler == nil {
+			continue
+		}
+		if !kb.matchKeypress(key, ch, mod) {
+			continue
+		}
+		rank, active := ranks[kb.context]
+		if !active {
+			continue
+		}
+		if g.matchView(v, kb) {
+			if viewKb == nil || rank < viewRank {
+				viewKb, viewRank = kb, rank
+			}
+			continue
+		}
+		if v != nil && g.matchView(v.ParentView, kb) && (parentViewKb == nil || rank < parentViewRank) {
+			parentViewKb, parentViewRank = kb, rank
+		}
+		if (globalKb == nil || rank < globalRank) && rank <= globalsUpTo && kb.viewName == "" && g.Modal() == nil && ((v != nil && !v.Editable) || (kb.ch == 0 && kb.key != KeyCtrlU && kb.key != KeyCtrlA && kb.key != KeyCtrlE)) {
+			globalKb, globalRank = kb, rank
+		}
+	}
+
+	return viewKb, parentViewKb, globalKb
+}
+
 // execKeybinding executes a given keybinding
 func (g *Gui) execKeybinding(v *View, kb *keybinding) error {
 	if g.isBlacklisted(kb.key) {
@@ -1625,6 +1962,9 @@ func (g *Gui) Suspend() error {
 
 	g.suspended = true
 
+	if g.extendedKeys != nil {
+		writeExtendedKeys(extendedKeysPop)
+	}
 	return g.screen.Suspend()
 }
 
@@ -1638,7 +1978,13 @@ func (g *Gui) Resume() error {
 
 	g.suspended = false
 
-	return g.screen.Resume()
+	if err := g.screen.Resume(); err != nil {
+		return err
+	}
+	if g.extendedKeys != nil {
+		writeExtendedKeys(extendedKeysPush)
+	}
+	return nil
 }
 
 // matchView returns if the keybinding matches the current view (and the view's context)
@@ -0,0 +1,248 @@
+package gocui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+// HelpViewName is the name of the view opened by OpenHelp.
+const HelpViewName = "gocui.help"
+
+// Binding describes a keybinding, as returned by ActiveKeybindings.
+type Binding struct {
+	// ViewName is the name of the view the keybinding belongs to, or "" for
+	// global keybindings.
+	ViewName string
+	// Context is the name of the context the keybinding belongs to, if any.
+	Context string
+	// Keys are the keys to press, one for regular keybindings and several for
+	// sequence keybindings.
+	Keys        []KeyPress
+	Category    string
+	Description string
+}
+
+// KeysString returns the keys of the binding as a key descriptor, like
+// "Ctrl+X Ctrl+S".
+func (b *Binding) KeysString() string {
+	return FormatSequence(b.Keys)
+}
+
+// DescribeKeybinding sets the category and description of the keybindings of
+// the view, in any context, triggered by the given keys: a key descriptor, or
+// a sequence of them, as parsed by ParseSequence. They are shown in the help
+// view.
+func (g *Gui) DescribeKeybinding(viewname string, keys string, category string, description string) error {
+	sequence, err := ParseSequence(keys)
+	if err != nil {
+		return err
+	}
+	presses := make([]keyPress, len(sequence))
+	for i, press := range sequence {
+		k, ch, err := getKey(press.Key)
+		if err != nil {
+			return err
+		}
+		presses[i] = keyPress{key: k, ch: ch, mod: press.Mod}
+	}
+
+	found := false
+	if len(presses) == 1 {
+		for _, kb := range g.keybindings {
+			if kb.viewName == viewname && kb.matchKeypress(presses[0].key, presses[0].ch, presses[0].mod) {
+				kb.category, kb.description = category, description
+				found = true
+			}
+		}
+	}
+	for _, sb := range g.sequenceBindings {
+		if sb.viewName == viewname && len(sb.presses) == len(presses) && keyPressesEqual(sb.presses, presses) {
+			sb.category, sb.description = category, description
+			found = true
+		}
+	}
+	if !found {
+		return errors.New("keybinding not found")
+	}
+	return nil
+}
+
+func keyPressesEqual(a []keyPress, b []keyPress) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ActiveKeybindings returns the keybindings that fire when a key is pressed
+// in the current view: for each key, the keybinding of the view if there is
+// one, or else that of its parent view, or else the global one, following
+// the precedence of the active contexts. Sequence keybindings that apply to
+// the view come last.
+func (g *Gui) ActiveKeybindings() []*Binding {
+	v := g.currentView
+	bindings := []*Binding{}
+	seen := map[keyPress]bool{}
+	for _, kb := range g.keybindings {
+		press := keyPress{key: kb.key, ch: kb.ch, mod: kb.mod}
+		if seen[press] {
+			continue
+		}
+
+		viewKb, parentViewKb, globalKb := g.matchingKeybindings(v, kb.key, kb.ch, kb.mod)
+		winner := viewKb
+		if winner == nil {
+			winner = parentViewKb
+		}
+		if winner == nil {
+			winner = globalKb
+		}
+		if winner == nil {
+			continue
+		}
+
+		seen[press] = true
+		bindings = append(bindings, &Binding{
+			ViewName:    winner.viewName,
+			Context:     winner.context,
+			Keys:        []KeyPress{{Key: keybindingKey(winner.key, winner.ch), Mod: winner.mod}},
+			Category:    winner.category,
+			Description: winner.description,
+		})
+	}
+
+	for _, sb := range g.sequenceBindings {
+		if !sb.applies(g, v) {
+			continue
+		}
+		keys := make([]KeyPress, len(sb.presses))
+		for i, press := range sb.presses {
+			keys[i] = KeyPress{Key: keybindingKey(press.key, press.ch), Mod: press.mod}
+		}
+		bindings = append(bindings, &Binding{
+			ViewName:    sb.viewName,
+			Keys:        keys,
+			Category:    sb.category,
+			Description: sb.description,
+		})
+	}
+
+	return bindings
+}
+
+// OpenHelp opens a cheat sheet of the active keybindings of the current view
+// that have a description, grouped by category, in a modal. Typing filters
+// the keybindings, and Esc closes it. It does nothing if the help is already
+// open.
+func (g *Gui) OpenHelp() error {
+	if _, err := g.View(HelpViewName); err == nil {
+		// the help is already open
+		return nil
+	}
+
+	bindings := []*Binding{}
+	for _, b := range g.ActiveKeybindings() {
+		if b.Description != "" {
+			bindings = append(bindings, b)
+		}
+	}
+
+	filter := ""
+	render := func(v *View) {
+		v.Title = "Keybindings"
+		if filter != "" {
+			v.Title = fmt.Sprintf("Keybindings (filter: %s)", filter)
+		}
+		v.SetContent(renderHelp(bindings, filter))
+		v.SetOrigin(0, 0)
+	}
+
+	maxX, maxY := g.Size()
+	_, err := g.PushModal(&Modal{
+		Name:   HelpViewName,
+		Width:  min(maxX-4, 70),
+		Height: maxY - 4,
+		OnCreate: func(v *View) error {
+			// the view is editable so that it gets the typed characters,
+			// which it uses as a filter
+			v.Editable = true
+			v.Editor = EditorFunc(func(v *View, key Key, ch rune, mod Modifier) bool {
+				switch {
+				case key == KeyBackspace || key == KeyBackspace2:
+					if runes := []rune(filter); len(runes) > 0 {
+						filter = string(runes[:len(runes)-1])
+					}
+				case key == KeySpace:
+					filter += " "
+				case ch != 0 && mod == ModNone:
+					filter += string(ch)
+				case key == KeyArrowDown:
+					v.ScrollDown(1)
+					return true
+				case key == KeyArrowUp:
+					v.ScrollUp(1)
+					return true
+				default:
+					return false
+				}
+				render(v)
+				return true
+			})
+			render(v)
+
+			// the binding is left over if the view was deleted directly
+			// rather than with PopModal
+			_ = g.DeleteKeybinding(HelpViewName, KeyEsc, ModNone)
+			return g.SetKeybinding(HelpViewName, KeyEsc, ModNone, func(g *Gui, v *View) error {
+				return g.PopModal()
+			})
+		},
+	})
+	return err
+}
+
+// renderHelp renders the bindings that match the filter, grouped by
+// category. Keybindings without a category are listed under "General".
+func renderHelp(bindings []*Binding, filter string) string {
+	filter = strings.ToLower(filter)
+	categories := []string{}
+	byCategory := map[string][]*Binding{}
+	keysWidth := 0
+	for _, b := range bindings {
+		category := b.Category
+		if category == "" {
+			category = "General"
+		}
+		keys := b.KeysString()
+		if filter != "" && !strings.Contains(strings.ToLower(category+" "+keys+" "+b.Description), filter) {
+			continue
+		}
+		if _, ok := byCategory[category]; !ok {
+			categories = append(categories, category)
+		}
+		byCategory[category] = append(byCategory[category], b)
+		keysWidth = max(keysWidth, len([]rune(keys)))
+	}
+
+	lines := []string{}
+	for i, category := range categories {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, category)
+		for _, b := range byCategory[category] {
+			keys := b.KeysString()
+			padding := strings.Repeat(" ", keysWidth-len([]rune(keys)))
+			lines = append(lines, fmt.Sprintf("  %s%s  %s", keys, padding, b.Description))
+		}
+	}
+	if len(lines) == 0 {
+		return "No matching keybindings"
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,116 @@
+package gocui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActiveKeybindings(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 80, Height: 30})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	parent, _ := g.SetView("parent", 0, 0, 10, 10, 0)
+	files, _ := g.SetView("files", 0, 0, 10, 10, 0)
+	files.ParentView = parent
+	_, _ = g.SetView("other", 0, 0, 10, 10, 0)
+	_, _ = g.SetCurrentView("files")
+
+	noop := func(*Gui, *View) error { return nil }
+	assert.NoError(t, g.SetKeybinding("", 'q', ModNone, noop))
+	assert.NoError(t, g.SetKeybinding("", 'd', ModNone, noop))
+	assert.NoError(t, g.SetKeybinding("parent", 'p', ModNone, noop))
+	assert.NoError(t, g.SetKeybinding("files", 'd', ModNone, noop))
+	assert.NoError(t, g.SetKeybinding("other", 'o', ModNone, noop))
+	assert.NoError(t, g.SetContextKeybinding("staging", "files", 's', ModNone, noop))
+	assert.NoError(t, g.SetSequenceKeybinding("files", []KeyPress{{Key: 'g'}, {Key: 'g'}}, noop))
+
+	assert.NoError(t, g.DescribeKeybinding("", "q", "", "quit"))
+	assert.NoError(t, g.DescribeKeybinding("files", "d", "Files", "delete"))
+	assert.NoError(t, g.DescribeKeybinding("parent", "p", "Files", "push"))
+	assert.NoError(t, g.DescribeKeybinding("files", "g g", "Navigation", "go to top"))
+	assert.Error(t, g.DescribeKeybinding("files", "x", "", "nothing"))
+
+	describe := func() []string {
+		result := []string{}
+		for _, b := range g.ActiveKeybindings() {
+			result = append(result, b.ViewName+" "+b.KeysString()+" "+b.Description)
+		}
+		return result
+	}
+	assert.EqualValues(t, []string{" q quit", "files d delete", "parent p push", "files g g go to top"}, describe())
+
+	g.PushContext(&KeybindingContext{Name: "staging"})
+	assert.EqualValues(t, []string{" q quit", "files d delete", "parent p push", "files s ", "files g g go to top"}, describe())
+
+	assert.EqualValues(t,
+		"General\n"+
+			"  q  quit\n"+
+			"\n"+
+			"Files\n"+
+			"  d  delete\n"+
+			"  p  push",
+		renderHelp(g.ActiveKeybindings()[:3], ""))
+}
+
+func TestOpenHelp(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 80, Height: 30})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	files, _ := g.SetView("files", 0, 0, 10, 10, 0)
+	_, _ = g.SetCurrentView("files")
+
+	noop := func(*Gui, *View) error { return nil }
+	assert.NoError(t, g.SetKeybinding("files", 'd', ModNone, noop))
+	assert.NoError(t, g.SetKeybinding("files", 'x', ModNone, noop))
+	assert.NoError(t, g.DescribeKeybinding("files", "d", "Files", "delete"))
+	assert.NoError(t, g.SetSequenceKeybinding("files", []KeyPress{{Key: KeyCtrlX}, {Key: KeyCtrlS}}, noop))
+	assert.NoError(t, g.DescribeKeybinding("files", "Ctrl+X Ctrl+S", "Files", "save"))
+
+	assert.NoError(t, g.OpenHelp())
+	help, err := g.View(HelpViewName)
+	assert.NoError(t, err)
+	assert.EqualValues(t, help, g.CurrentView())
+	assert.EqualValues(t, "Files\n  d              delete\n  Ctrl+X Ctrl+S  save", help.Buffer())
+
+	for _, ch := range "sav" {
+		assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Ch: ch}))
+	}
+	assert.EqualValues(t, "Keybindings (filter: sav)", help.Title)
+	assert.EqualValues(t, "Files\n  Ctrl+X Ctrl+S  save", help.Buffer())
+
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Ch: 'z'}))
+	assert.EqualValues(t, "No matching keybindings", help.Buffer())
+
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Key: KeyEsc}))
+	_, err = g.View(HelpViewName)
+	assert.Error(t, err)
+	assert.EqualValues(t, files, g.CurrentView())
+}
+
+func TestOpenHelpTwice(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 80, Height: 30})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	g.StrictKeybindings = true
+	files, _ := g.SetView("files", 0, 0, 10, 10, 0)
+	_, _ = g.SetCurrentView("files")
+
+	// opening the help while it's open does nothing
+	assert.NoError(t, g.OpenHelp())
+	assert.NoError(t, g.OpenHelp())
+	assert.Len(t, g.modals, 1)
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Key: KeyEsc}))
+	assert.Nil(t, g.Modal())
+	assert.EqualValues(t, files, g.CurrentView())
+
+	// nor does deleting its view directly break it
+	assert.NoError(t, g.OpenHelp())
+	assert.NoError(t, g.DeleteView(HelpViewName))
+	assert.NoError(t, g.OpenHelp())
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Key: KeyEsc}))
+	assert.Nil(t, g.Modal())
+}
@@ -0,0 +1,327 @@
+package gocui
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// the number of entries a History keeps when Limit is zero
+const defaultHistoryLimit = 500
+
+// HistoryStore persists the entries of a History, so that they survive
+// restarts.
+type HistoryStore interface {
+	Load() ([]string, error)
+	Save(entries []string) error
+}
+
+// FileHistoryStore keeps history entries in a file, one per line. Entries are
+// quoted, so they may span multiple lines.
+type FileHistoryStore struct {
+	Path string
+}
+
+func NewFileHistoryStore(path string) *FileHistoryStore {
+	return &FileHistoryStore{Path: path}
+}
+
+// Load reads the entries from the file. A missing file holds no entries.
+// Lines that can't be unquoted are skipped.
+func (s *FileHistoryStore) Load() ([]string, error) {
+	f, err := os.Open(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		entry, err := strconv.Unquote(scanner.Text())
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
+
+// Save replaces the contents of the file with the given entries.
+func (s *FileHistoryStore) Save(entries []string) error {
+	var b strings.Builder
+	for _, entry := range entries {
+		b.WriteString(strconv.Quote(entry))
+		b.WriteByte('\n')
+	}
+
+	// write to a temporary file first, so that a crash can't leave us with a
+	// truncated history
+	tmp := filepath.Join(filepath.Dir(s.Path), "."+filepath.Base(s.Path)+".tmp")
+	if err := os.WriteFile(tmp, []byte(b.String()), 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.Path)
+}
+
+// History remembers the input submitted in a view. Attach it to an editable
+// view by setting View.History; SimpleEditor then recalls older entries with
+// the up arrow when the cursor is on the first line, newer ones with the down
+// arrow when the cursor is on the last line, and starts an incremental reverse
+// search with SimpleEditorKeys.HistorySearch.
+//
+// Entries are added by the application, typically from the keybinding that
+// submits the input:
+//
+//	v.History.Add(v.TextArea.GetUnwrappedContent())
+//	v.ClearTextArea()
+type History struct {
+	// Limit is the maximum number of entries to keep. Older entries are
+	// dropped first. 0 means the default of 500.
+	Limit int
+
+	store   HistoryStore
+	entries []string
+
+	// the entry being shown while browsing, len(entries) if we're not
+	// browsing, and the input that was there before we started browsing
+	index int
+	draft string
+
+	searching    bool
+	searchQuery  string
+	searchFailed bool
+	// the input, cursor and entry to restore when the search is cancelled
+	searchOrigin      textAreaState
+	searchOriginIndex int
+}
+
+// NewHistory returns a History that loads its entries from the given store
+// and saves them back whenever an entry is added. store may be nil if the
+// history doesn't need to be persisted.
+func NewHistory(store HistoryStore) (*History, error) {
+	h := &History{store: store}
+	if store != nil {
+		entries, err := store.Load()
+		if err != nil {
+			return h, err
+		}
+		h.entries = entries
+		h.trim()
+	}
+	h.index = len(h.entries)
+
+	return h, nil
+}
+
+func (h *History) limit() int {
+	if h.Limit == 0 {
+		return defaultHistoryLimit
+	}
+
+	return h.Limit
+}
+
+func (h *History) trim() {
+	if excess := len(h.entries) - h.limit(); excess > 0 {
+		h.entries = h.entries[excess:]
+	}
+}
+
+// Entries returns the entries from oldest to newest.
+func (h *History) Entries() []string {
+	return append([]string{}, h.entries...)
+}
+
+// Add appends an entry to the history and saves it to the store, if any.
+// Blank entries and repetitions of the newest entry are ignored. Adding an
+// entry ends browsing and searching.
+func (h *History) Add(entry string) error {
+	h.reset()
+
+	if strings.TrimSpace(entry) == "" ||
+		(len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry) {
+		return nil
+	}
+
+	h.entries = append(h.entries, entry)
+	h.trim()
+	h.index = len(h.entries)
+
+	if h.store == nil {
+		return nil
+	}
+
+	return h.store.Save(h.entries)
+}
+
+func (h *History) reset() {
+	h.index = len(h.entries)
+	h.draft = ""
+	h.searching = false
+}
+
+// Prev replaces the input with the previous (older) entry. It returns false
+// if there is none.
+func (h *History) Prev(ta *TextArea) bool {
+	if h.index == 0 {
+		return false
+	}
+
+	if h.index == len(h.entries) {
+		h.draft = ta.GetUnwrappedContent()
+	}
+	h.index--
+	replaceContent(ta, h.entries[h.index])
+
+	return true
+}
+
+// Next replaces the input with the next (newer) entry, or with the input that
+// was there before browsing started. It returns false if we're not browsing.
+func (h *History) Next(ta *TextArea) bool {
+	if h.index >= len(h.entries) {
+		return false
+	}
+
+	h.index++
+	if h.index == len(h.entries) {
+		replaceContent(ta, h.draft)
+		h.draft = ""
+	} else {
+		replaceContent(ta, h.entries[h.index])
+	}
+
+	return true
+}
+
+// replaceContent replaces the content of the text area as a single undoable
+// change, leaving the cursor at the end.
+func replaceContent(ta *TextArea, content string) {
+	ta.SelectAll()
+	ta.ReplaceSelection(content)
+}
+
+// Searching reports whether an incremental reverse search is in progress.
+func (h *History) Searching() bool {
+	return h.searching
+}
+
+// SearchIndicator describes the search in progress the way readline does,
+// e.g. "(reverse-i-search)`foo': ". It returns "" if we're not searching.
+func (h *History) SearchIndicator() string {
+	if !h.searching {
+		return ""
+	}
+
+	prefix := ""
+	if h.searchFailed {
+		prefix = "failed "
+	}
+
+	return "(" + prefix + "reverse-i-search)`" + h.searchQuery + "': "
+}
+
+// Search starts an incremental reverse search, or, if one is in progress,
+// looks for the next older entry matching the query.
+func (h *History) Search(ta *TextArea) {
+	if !h.searching {
+		h.searching = true
+		h.searchQuery = ""
+		h.searchFailed = false
+		h.searchOrigin = ta.state()
+		h.searchOriginIndex = h.index
+		return
+	}
+
+	h.searchFrom(ta, h.index-1)
+}
+
+// searchFrom shows the newest entry at or before the given index that
+// contains the query.
+func (h *History) searchFrom(ta *TextArea, index int) {
+	if h.searchQuery == "" {
+		h.searchFailed = false
+		return
+	}
+
+	for i := min(index, len(h.entries)-1); i >= 0; i-- {
+		pos := strings.Index(h.entries[i], h.searchQuery)
+		if pos < 0 {
+			continue
+		}
+
+		if h.index == len(h.entries) {
+			h.draft = string(h.searchOrigin.content)
+		}
+		h.index = i
+		h.searchFailed = false
+		replaceContent(ta, h.entries[i])
+		ta.SetCursor(len([]rune(h.entries[i][:pos])))
+		return
+	}
+
+	h.searchFailed = true
+}
+
+// CancelSearch ends the search and restores the input that was there before
+// it started.
+func (h *History) CancelSearch(ta *TextArea) {
+	if !h.searching {
+		return
+	}
+
+	h.searching = false
+	if !runesEqual(ta.content, h.searchOrigin.content) {
+		replaceContent(ta, string(h.searchOrigin.content))
+	}
+	ta.SetCursor(h.searchOrigin.cursor)
+	h.index = h.searchOriginIndex
+	if h.index == len(h.entries) {
+		h.draft = ""
+	}
+}
+
+// AcceptSearch ends the search, keeping the matching entry as the input.
+// Browsing continues from that entry.
+func (h *History) AcceptSearch() {
+	h.searching = false
+}
+
+// searchKey handles a key press during a search. It returns false if the key
+// ends the search and should be handled as usual.
+func (h *History) searchKey(ta *TextArea, key Key, ch rune, mod Modifier) bool {
+	switch {
+	case SimpleEditorKeys.HistorySearch.Matches(key, ch, mod):
+		h.Search(ta)
+	case key == KeyEsc || key == KeyCtrlG:
+		h.CancelSearch(ta)
+	case key == KeyBackspace || key == KeyBackspace2:
+		if h.searchQuery != "" {
+			query := []rune(h.searchQuery)
+			h.searchQuery = string(query[:len(query)-1])
+			h.searchFrom(ta, len(h.entries)-1)
+		}
+	case key == KeySpace:
+		h.searchQuery += " "
+		h.searchFrom(ta, h.index)
+	case ch != 0 && mod == ModNone && unicode.IsPrint(ch):
+		h.searchQuery += string(ch)
+		h.searchFrom(ta, h.index)
+	case key == KeyEnter:
+		h.AcceptSearch()
+	default:
+		h.AcceptSearch()
+		return false
+	}
+
+	return true
+}
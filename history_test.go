@@ -0,0 +1,157 @@
+package gocui
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistoryBrowsing(t *testing.T) {
+	h, err := NewHistory(nil)
+	assert.NoError(t, err)
+	for _, entry := range []string{"one", "two", "  ", "two", "three"} {
+		assert.NoError(t, h.Add(entry))
+	}
+	assert.EqualValues(t, []string{"one", "two", "three"}, h.Entries())
+
+	ta := &TextArea{}
+	ta.TypeString("draft")
+
+	assert.True(t, h.Prev(ta))
+	assert.EqualValues(t, "three", ta.GetUnwrappedContent())
+	assert.EqualValues(t, 5, ta.GetCursor())
+	assert.True(t, h.Prev(ta))
+	assert.True(t, h.Prev(ta))
+	assert.EqualValues(t, "one", ta.GetUnwrappedContent())
+	assert.False(t, h.Prev(ta))
+	assert.EqualValues(t, "one", ta.GetUnwrappedContent())
+
+	assert.True(t, h.Next(ta))
+	assert.EqualValues(t, "two", ta.GetUnwrappedContent())
+	assert.True(t, h.Next(ta))
+	assert.True(t, h.Next(ta))
+	assert.EqualValues(t, "draft", ta.GetUnwrappedContent())
+	assert.False(t, h.Next(ta))
+}
+
+func TestHistoryLimit(t *testing.T) {
+	h, err := NewHistory(nil)
+	assert.NoError(t, err)
+	h.Limit = 2
+	for _, entry := range []string{"one", "two", "three"} {
+		assert.NoError(t, h.Add(entry))
+	}
+	assert.EqualValues(t, []string{"two", "three"}, h.Entries())
+}
+
+func TestHistorySearch(t *testing.T) {
+	tests := []struct {
+		name              string
+		keys              []Key
+		runes             string
+		expectedContent   string
+		expectedCursor    int
+		expectedSearching bool
+		expectedIndicator string
+	}{
+		{
+			name:              "start",
+			keys:              []Key{KeyCtrlR},
+			expectedContent:   "draft",
+			expectedCursor:    5,
+			expectedSearching: true,
+			expectedIndicator: "(reverse-i-search)`': ",
+		},
+		{
+			name:              "match",
+			keys:              []Key{KeyCtrlR},
+			runes:             "ma",
+			expectedContent:   "git log --oneline | make",
+			expectedCursor:    20,
+			expectedSearching: true,
+			expectedIndicator: "(reverse-i-search)`ma': ",
+		},
+		{
+			name:              "older match",
+			keys:              []Key{KeyCtrlR, KeyCtrlR},
+			runes:             "ma",
+			expectedContent:   "make test",
+			expectedCursor:    0,
+			expectedSearching: true,
+			expectedIndicator: "(reverse-i-search)`ma': ",
+		},
+		{
+			name:              "no match",
+			keys:              []Key{KeyCtrlR},
+			runes:             "xyz",
+			expectedContent:   "draft",
+			expectedCursor:    5,
+			expectedSearching: true,
+			expectedIndicator: "(failed reverse-i-search)`xyz': ",
+		},
+		{
+			name:              "cancel",
+			keys:              []Key{KeyCtrlR, KeyCtrlR, KeyEsc},
+			runes:             "ma",
+			expectedContent:   "draft",
+			expectedCursor:    5,
+			expectedSearching: false,
+		},
+		{
+			name:              "accept with enter",
+			keys:              []Key{KeyCtrlR, KeyEnter},
+			runes:             "log",
+			expectedContent:   "git log --oneline | make",
+			expectedCursor:    4,
+			expectedSearching: false,
+		},
+		{
+			name:              "accept with another key",
+			keys:              []Key{KeyCtrlR, KeyArrowRight},
+			runes:             "log",
+			expectedContent:   "git log --oneline | make",
+			expectedCursor:    5,
+			expectedSearching: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := NewView("name", 0, 0, 40, 10, OutputNormal)
+			v.History, _ = NewHistory(nil)
+			for _, entry := range []string{"make test", "ls", "git log --oneline | make"} {
+				assert.NoError(t, v.History.Add(entry))
+			}
+			v.TextArea.TypeString("draft")
+
+			// the runes are typed after the first key
+			SimpleEditor(v, test.keys[0], 0, ModNone)
+			for _, ch := range test.runes {
+				SimpleEditor(v, 0, ch, ModNone)
+			}
+			for _, key := range test.keys[1:] {
+				SimpleEditor(v, key, 0, ModNone)
+			}
+
+			assert.EqualValues(t, test.expectedContent, v.TextArea.GetUnwrappedContent())
+			assert.EqualValues(t, test.expectedCursor, v.TextArea.GetCursor())
+			assert.EqualValues(t, test.expectedSearching, v.History.Searching())
+			assert.EqualValues(t, test.expectedIndicator, v.History.SearchIndicator())
+		})
+	}
+}
+
+func TestFileHistoryStore(t *testing.T) {
+	store := NewFileHistoryStore(filepath.Join(t.TempDir(), "history"))
+
+	h, err := NewHistory(store)
+	assert.NoError(t, err)
+	assert.Empty(t, h.Entries())
+	assert.NoError(t, h.Add("one"))
+	assert.NoError(t, h.Add("two\nlines"))
+
+	h, err = NewHistory(store)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"one", "two\nlines"}, h.Entries())
+}
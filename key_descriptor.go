@@ -0,0 +1,391 @@
+package gocui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// KeyParseError is returned when a key descriptor can't be parsed.
+type KeyParseError struct {
+	// Input is the key descriptor that was parsed.
+	Input string
+	// Reason tells what's wrong with it.
+	Reason string
+	// Err is ErrNoSuchKeybind if the key name is unknown, and nil otherwise.
+	Err error
+}
+
+func (e *KeyParseError) Error() string {
+	return fmt.Sprintf("invalid key %q: %s", e.Input, e.Reason)
+}
+
+// modifierNames are the names of the modifiers in key descriptors, in the
+// order Format writes them in.
+var modifierNames = []struct {
+	mod   Modifier
+	names []string
+}{
+	{ModRepeat, []string{"repeat"}},
+	{ModRelease, []string{"release"}},
+	{ModCtrl, []string{"ctrl", "control", "c"}},
+	{ModAlt, []string{"alt", "opt", "option", "a"}},
+	{ModShift, []string{"shift", "s"}},
+	{ModMeta, []string{"meta", "cmd", "super", "m"}},
+}
+
+// keyNames maps the lowercase names of keys to keys.
+var keyNames = map[string]Key{
+	"insert":          KeyInsert,
+	"ins":             KeyInsert,
+	"delete":          KeyDelete,
+	"del":             KeyDelete,
+	"home":            KeyHome,
+	"end":             KeyEnd,
+	"pgup":            KeyPgup,
+	"pageup":          KeyPgup,
+	"pgdn":            KeyPgdn,
+	"pagedown":        KeyPgdn,
+	"up":              KeyArrowUp,
+	"down":            KeyArrowDown,
+	"left":            KeyArrowLeft,
+	"right":           KeyArrowRight,
+	"tab":             KeyTab,
+	"backtab":         KeyBacktab,
+	"enter":           KeyEnter,
+	"return":          KeyEnter,
+	"esc":             KeyEsc,
+	"escape":          KeyEsc,
+	"space":           KeySpace,
+	"backspace":       KeyBackspace,
+	"backspace2":      KeyBackspace2,
+	"mouseleft":       MouseLeft,
+	"mouseright":      MouseRight,
+	"mousemiddle":     MouseMiddle,
+	"mouserelease":    MouseRelease,
+	"mousewheelup":    MouseWheelUp,
+	"mousewheeldown":  MouseWheelDown,
+	"mousewheelleft":  MouseWheelLeft,
+	"mousewheelright": MouseWheelRight,
+}
+
+func init() {
+	for i := 1; i <= 12; i++ {
+		keyNames[fmt.Sprintf("f%d", i)] = KeyF1 + Key(i-1)
+	}
+	// the names Parse used to accept, like "CtrlA" or "ArrowUp"
+	for name, key := range translate {
+		lower := strings.ToLower(name)
+		if _, ok := keyNames[lower]; !ok {
+			keyNames[lower] = key
+		}
+	}
+}
+
+// ctrlRunes maps the runes that form a control key together with Ctrl to
+// that key.
+var ctrlRunes = map[rune]Key{
+	' ':  KeyCtrlSpace,
+	'2':  KeyCtrl2,
+	'@':  KeyCtrl2,
+	'3':  KeyCtrl3,
+	'[':  KeyCtrlLsqBracket,
+	'4':  KeyCtrl4,
+	'\\': KeyCtrlBackslash,
+	'5':  KeyCtrl5,
+	']':  KeyCtrlRsqBracket,
+	'6':  KeyCtrl6,
+	'^':  KeyCtrl6,
+	'7':  KeyCtrl7,
+	'/':  KeyCtrlSlash,
+	'_':  KeyCtrlUnderscore,
+	'8':  KeyCtrl8,
+	'~':  KeyCtrlTilde,
+}
+
+// shiftKeys maps keys to the keys reported when they are pressed together
+// with Shift only.
+var shiftKeys = map[Key]Key{
+	KeyArrowUp:    KeyShiftArrowUp,
+	KeyArrowDown:  KeyShiftArrowDown,
+	KeyArrowLeft:  KeyShiftArrowLeft,
+	KeyArrowRight: KeyShiftArrowRight,
+	KeyHome:       KeyShiftHome,
+	KeyEnd:        KeyShiftEnd,
+	KeyTab:        KeyBacktab,
+}
+
+type keyFormat struct {
+	mod  Modifier
+	name string
+}
+
+// keyFormats are the canonical names of keys, along with the modifiers that
+// are part of the key itself. Keys sharing the same value, like KeyTab and
+// KeyCtrlI, get the name of the most common one. MouseLeft and MouseRight
+// share their values with KeyShiftArrowDown and KeyShiftArrowUp, so they are
+// formatted as those.
+var keyFormats = map[Key]keyFormat{
+	KeyInsert:          {ModNone, "Insert"},
+	KeyDelete:          {ModNone, "Delete"},
+	KeyHome:            {ModNone, "Home"},
+	KeyEnd:             {ModNone, "End"},
+	KeyPgup:            {ModNone, "PgUp"},
+	KeyPgdn:            {ModNone, "PgDn"},
+	KeyArrowUp:         {ModNone, "Up"},
+	KeyArrowDown:       {ModNone, "Down"},
+	KeyArrowLeft:       {ModNone, "Left"},
+	KeyArrowRight:      {ModNone, "Right"},
+	KeyShiftArrowUp:    {ModShift, "Up"},
+	KeyShiftArrowDown:  {ModShift, "Down"},
+	KeyShiftArrowLeft:  {ModShift, "Left"},
+	KeyShiftArrowRight: {ModShift, "Right"},
+	KeyShiftHome:       {ModShift, "Home"},
+	KeyShiftEnd:        {ModShift, "End"},
+	KeyTab:             {ModNone, "Tab"},
+	KeyBacktab:         {ModShift, "Tab"},
+	KeyEnter:           {ModNone, "Enter"},
+	KeyAltEnter:        {ModAlt, "Enter"},
+	KeyEsc:             {ModNone, "Esc"},
+	KeySpace:           {ModNone, "Space"},
+	KeyBackspace:       {ModNone, "Backspace"},
+	KeyBackspace2:      {ModNone, "Backspace2"},
+	KeyCtrlSpace:       {ModCtrl, "Space"},
+	KeyCtrlBackslash:   {ModCtrl, "\\"},
+	KeyCtrlRsqBracket:  {ModCtrl, "]"},
+	KeyCtrl6:           {ModCtrl, "^"},
+	KeyCtrlUnderscore:  {ModCtrl, "_"},
+	MouseWheelUp:       {ModNone, "MouseWheelUp"},
+	MouseWheelDown:     {ModNone, "MouseWheelDown"},
+	MouseWheelLeft:     {ModNone, "MouseWheelLeft"},
+	MouseWheelRight:    {ModNone, "MouseWheelRight"},
+	MouseMiddle:        {ModNone, "MouseMiddle"},
+	MouseRelease:       {ModNone, "MouseRelease"},
+}
+
+func init() {
+	for i := 1; i <= 12; i++ {
+		keyFormats[KeyF1+Key(i-1)] = keyFormat{ModNone, fmt.Sprintf("F%d", i)}
+	}
+	for ch := 'A'; ch <= 'Z'; ch++ {
+		key := KeyCtrlA + Key(ch-'A')
+		if _, ok := keyFormats[key]; !ok {
+			keyFormats[key] = keyFormat{ModCtrl, string(ch)}
+		}
+	}
+}
+
+// parseKeyDescriptor parses a key descriptor like "q", "Ctrl+Shift+Up" or
+// "Alt+Enter".
+func parseKeyDescriptor(input string) (interface{}, Modifier, error) {
+	fail := func(reason string, err error) (interface{}, Modifier, error) {
+		return nil, ModNone, &KeyParseError{Input: input, Reason: reason, Err: err}
+	}
+	if input == "" {
+		return fail("empty key", nil)
+	}
+
+	// everything up to the last '+' is a modifier, unless the key itself is
+	// '+', as in "Ctrl++"
+	mod := ModNone
+	rest := input
+	for {
+		idx := strings.Index(rest, "+")
+		if idx <= 0 || idx == len(rest)-1 {
+			break
+		}
+		name := strings.ToLower(rest[:idx])
+		found := false
+		for _, m := range modifierNames {
+			for _, n := range m.names {
+				if n == name {
+					if mod&m.mod != 0 {
+						return fail(fmt.Sprintf("modifier %q is repeated", rest[:idx]), nil)
+					}
+					mod |= m.mod
+					found = true
+				}
+			}
+		}
+		if !found {
+			return fail(fmt.Sprintf("unknown modifier %q", rest[:idx]), nil)
+		}
+		rest = rest[idx+1:]
+	}
+
+	if rest == " " {
+		rest = "space"
+	}
+	if utf8.RuneCountInString(rest) == 1 {
+		ch, _ := utf8.DecodeRuneInString(rest)
+		if mod&ModCtrl != 0 {
+			// Shift is kept, since Ctrl+Shift+A can be told apart from
+			// Ctrl+A with extended keys. Ctrl with other characters, like
+			// Ctrl+1, can only be pressed with extended keys
+			if unicode.IsLetter(ch) && ch < unicode.MaxASCII {
+				return KeyCtrlA + Key(unicode.ToUpper(ch)-'A'), mod &^ ModCtrl, nil
+			}
+			if key, ok := ctrlRunes[ch]; ok && mod&ModShift == 0 {
+				return key, mod &^ ModCtrl, nil
+			}
+		}
+		if mod&ModShift != 0 {
+			if !unicode.IsLetter(ch) {
+				return fail(fmt.Sprintf("Shift can't be combined with %q; use the shifted character instead", ch), nil)
+			}
+			ch = unicode.ToUpper(ch)
+			mod &^= ModShift
+		}
+		return ch, mod, nil
+	}
+
+	key, ok := keyNames[strings.ToLower(rest)]
+	if !ok {
+		return fail(fmt.Sprintf("unknown key %q", rest), ErrNoSuchKeybind)
+	}
+
+	switch {
+	case mod == ModShift && shiftKeys[key] != 0:
+		return shiftKeys[key], ModNone, nil
+	case mod == ModAlt && key == KeyEnter:
+		return KeyAltEnter, ModNone, nil
+	case mod&ModCtrl != 0 && key == KeySpace:
+		return KeyCtrlSpace, mod &^ ModCtrl, nil
+	}
+
+	return key, mod, nil
+}
+
+// Format returns the canonical key descriptor of a key, a rune or a Key, with
+// a modifier, e.g. "Ctrl+Alt+K" or "Shift+F5". Parse accepts it and returns
+// the same key and modifier.
+func Format(key interface{}, mod Modifier) string {
+	k, ch, err := getKey(key)
+	if err != nil {
+		return ""
+	}
+
+	name := ""
+	switch {
+	case ch == ' ':
+		name = "Space"
+	case ch != 0:
+		name = string(ch)
+	default:
+		format, ok := keyFormats[k]
+		if !ok {
+			return fmt.Sprintf("Key(%d)", int(k))
+		}
+		name = format.name
+		mod |= format.mod
+	}
+
+	parts := []string{}
+	for _, m := range modifierNames {
+		if mod&m.mod != 0 {
+			parts = append(parts, strings.Title(m.names[0]))
+		}
+	}
+	return strings.Join(append(parts, name), "+")
+}
+
+// ParseSequence parses a sequence of key descriptors separated by spaces, like
+// "Ctrl+X Ctrl+S" or "g g", for SetSequenceKeybinding.
+func ParseSequence(input string) ([]KeyPress, error) {
+	fields := strings.Fields(input)
+	if len(fields) == 0 {
+		return nil, ErrInvalidKeySequence
+	}
+
+	sequence := make([]KeyPress, len(fields))
+	for i, field := range fields {
+		key, mod, err := Parse(field)
+		if err != nil {
+			return nil, err
+		}
+		sequence[i] = KeyPress{Key: key, Mod: mod}
+	}
+	return sequence, nil
+}
+
+// FormatSequence returns the canonical descriptor of a key sequence.
+func FormatSequence(sequence []KeyPress) string {
+	parts := make([]string, len(sequence))
+	for i, press := range sequence {
+		parts[i] = Format(press.Key, press.Mod)
+	}
+	return strings.Join(parts, " ")
+}
+
+// LoadKeybindings reads keybindings from r and sets them, binding them to the
+// handlers of actions. Each line binds an action to a key, or to a sequence
+// of keys separated by spaces; lines starting with '#' are comments. The
+// bindings apply to all views, except those following a [view] line, which
+// apply to that view:
+//
+//	quit = Ctrl+C
+//	save = Ctrl+X Ctrl+S
+//
+//	[files]
+//	stage = Space
+//	top = g g
+//
+// The actions are used as the descriptions of the keybindings. Nothing is set
+// if any line is invalid, and the error tells which one.
+func (g *Gui) LoadKeybindings(r io.Reader, actions map[string]func(*Gui, *View) error) error {
+	type binding struct {
+		viewName string
+		sequence []KeyPress
+		action   string
+	}
+
+	bindings := []binding{}
+	viewName := ""
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			viewName = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		idx := strings.Index(line, "=")
+		if idx < 0 {
+			return fmt.Errorf("line %d: expected \"action = key\", got %q", lineNum, line)
+		}
+		action := strings.TrimSpace(line[:idx])
+		if _, ok := actions[action]; !ok {
+			return fmt.Errorf("line %d: unknown action %q", lineNum, action)
+		}
+		sequence, err := ParseSequence(line[idx+1:])
+		if err != nil {
+			return fmt.Errorf("line %d: %v", lineNum, err)
+		}
+		bindings = append(bindings, binding{viewName: viewName, sequence: sequence, action: action})
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	for _, b := range bindings {
+		var err error
+		if len(b.sequence) == 1 {
+			err = g.SetKeybinding(b.viewName, b.sequence[0].Key, b.sequence[0].Mod, actions[b.action])
+		} else {
+			err = g.SetSequenceKeybinding(b.viewName, b.sequence, actions[b.action])
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", FormatSequence(b.sequence), err)
+		}
+		if err := g.DescribeKeybinding(b.viewName, FormatSequence(b.sequence), "", b.action); err != nil {
+			return err
+		}
+	}
+	return nil
+}
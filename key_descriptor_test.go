@@ -0,0 +1,142 @@
+package gocui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedKey    interface{}
+		expectedMod    Modifier
+		expectedFormat string
+		expectedError  string
+	}{
+		{input: "q", expectedKey: 'q', expectedFormat: "q"},
+		{input: "+", expectedKey: '+', expectedFormat: "+"},
+		{input: "Alt++", expectedKey: '+', expectedMod: ModAlt, expectedFormat: "Alt++"},
+		{input: "Ctrl+Alt+k", expectedKey: KeyCtrlK, expectedMod: ModAlt, expectedFormat: "Ctrl+Alt+K"},
+		{input: "ctrl+a", expectedKey: KeyCtrlA, expectedFormat: "Ctrl+A"},
+		{input: "CtrlA", expectedKey: KeyCtrlA, expectedFormat: "Ctrl+A"},
+		{input: "alt+K", expectedKey: 'K', expectedMod: ModAlt, expectedFormat: "Alt+K"},
+		{input: "Shift+k", expectedKey: 'K', expectedFormat: "K"},
+		{input: "Meta+x", expectedKey: 'x', expectedMod: ModMeta, expectedFormat: "Meta+x"},
+		{input: "Ctrl+Shift+a", expectedKey: KeyCtrlA, expectedMod: ModShift, expectedFormat: "Ctrl+Shift+A"},
+		{input: "Ctrl+1", expectedKey: '1', expectedMod: ModCtrl, expectedFormat: "Ctrl+1"},
+		{input: "Ctrl+Enter", expectedKey: KeyEnter, expectedMod: ModCtrl, expectedFormat: "Ctrl+Enter"},
+		{input: "release+ctrl+k", expectedKey: KeyCtrlK, expectedMod: ModRelease, expectedFormat: "Release+Ctrl+K"},
+		{input: "Alt+Repeat+Down", expectedKey: KeyArrowDown, expectedMod: ModRepeat | ModAlt, expectedFormat: "Repeat+Alt+Down"},
+		{input: "Ctrl+Shift+Up", expectedKey: KeyArrowUp, expectedMod: ModCtrl | ModShift, expectedFormat: "Ctrl+Shift+Up"},
+		{input: "Shift+Up", expectedKey: KeyShiftArrowUp, expectedFormat: "Shift+Up"},
+		{input: "ArrowUp", expectedKey: KeyArrowUp, expectedFormat: "Up"},
+		{input: "Shift+F5", expectedKey: KeyF5, expectedMod: ModShift, expectedFormat: "Shift+F5"},
+		{input: "f12", expectedKey: KeyF12, expectedFormat: "F12"},
+		{input: "Shift+Tab", expectedKey: KeyBacktab, expectedFormat: "Shift+Tab"},
+		{input: "Alt+Enter", expectedKey: KeyAltEnter, expectedFormat: "Alt+Enter"},
+		{input: "Ctrl+Space", expectedKey: KeyCtrlSpace, expectedFormat: "Ctrl+Space"},
+		{input: "space", expectedKey: KeySpace, expectedFormat: "Space"},
+		{input: " ", expectedKey: KeySpace, expectedFormat: "Space"},
+		{input: "Ctrl+\\", expectedKey: KeyCtrlBackslash, expectedFormat: "Ctrl+\\"},
+		{input: "PageDown", expectedKey: KeyPgdn, expectedFormat: "PgDn"},
+		{input: "Alt+Esc", expectedKey: KeyEsc, expectedMod: ModAlt, expectedFormat: "Alt+Esc"},
+		{input: "MouseWheelUp", expectedKey: MouseWheelUp, expectedFormat: "MouseWheelUp"},
+		{input: "", expectedError: `invalid key "": empty key`},
+		{input: "Hyper+a", expectedError: `invalid key "Hyper+a": unknown modifier "Hyper"`},
+		{input: "Ctrl+Ctrl+a", expectedError: `invalid key "Ctrl+Ctrl+a": modifier "Ctrl" is repeated`},
+		{input: "Shift+1", expectedError: `invalid key "Shift+1": Shift can't be combined with '1'; use the shifted character instead`},
+		{input: "Alt+Foo", expectedError: `invalid key "Alt+Foo": unknown key "Foo"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			key, mod, err := Parse(test.input)
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.EqualValues(t, test.expectedKey, key)
+			assert.EqualValues(t, test.expectedMod, mod)
+
+			formatted := Format(key, mod)
+			assert.EqualValues(t, test.expectedFormat, formatted)
+
+			// the canonical form parses to the same key
+			key, mod, err = Parse(formatted)
+			assert.NoError(t, err)
+			assert.EqualValues(t, test.expectedKey, key)
+			assert.EqualValues(t, test.expectedMod, mod)
+		})
+	}
+
+	_, _, err := Parse("Nope")
+	assert.Equal(t, ErrNoSuchKeybind, err.(*KeyParseError).Err)
+}
+
+func TestParseSequence(t *testing.T) {
+	sequence, err := ParseSequence("Ctrl+X  ctrl+s")
+	assert.NoError(t, err)
+	assert.EqualValues(t, []KeyPress{{Key: KeyCtrlX}, {Key: KeyCtrlS}}, sequence)
+	assert.EqualValues(t, "Ctrl+X Ctrl+S", FormatSequence(sequence))
+
+	_, err = ParseSequence(" ")
+	assert.Equal(t, ErrInvalidKeySequence, err)
+}
+
+func TestLoadKeybindings(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 40, Height: 20})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	g.KeySequenceTimeout = 0
+	_, _ = g.SetView("files", 0, 0, 10, 10, 0)
+	_, _ = g.SetCurrentView("files")
+
+	pressed := []string{}
+	actions := map[string]func(*Gui, *View) error{}
+	for _, name := range []string{"quit", "save", "stage", "top"} {
+		name := name
+		actions[name] = func(*Gui, *View) error {
+			pressed = append(pressed, name)
+			return nil
+		}
+	}
+
+	config := `
+# global bindings
+quit = Ctrl+C
+save = Ctrl+X Ctrl+S
+
+[files]
+stage = Space
+top = g g
+`
+	assert.NoError(t, g.LoadKeybindings(strings.NewReader(config), actions))
+	for _, ev := range []GocuiEvent{{Key: KeyCtrlC}, {Key: KeyCtrlX}, {Key: KeyCtrlS}, {Key: KeySpace}, {Ch: 'g'}, {Ch: 'g'}} {
+		ev := ev
+		ev.Type = eventKey
+		assert.NoError(t, g.onKey(&ev))
+	}
+	assert.EqualValues(t, []string{"quit", "save", "stage", "top"}, pressed)
+
+	errorTests := []struct {
+		config        string
+		expectedError string
+	}{
+		{config: "quit Ctrl+C", expectedError: `line 1: expected "action = key", got "quit Ctrl+C"`},
+		{config: "\nexplode = x", expectedError: `line 2: unknown action "explode"`},
+		{config: "quit = q\nquit = Ctrl+Q+", expectedError: `line 2: invalid key "Ctrl+Q+": unknown key "Q+"`},
+		{config: "quit =", expectedError: `line 1: invalid key sequence`},
+	}
+	for _, test := range errorTests {
+		t.Run(test.expectedError, func(t *testing.T) {
+			count := len(g.keybindings)
+			assert.EqualError(t, g.LoadKeybindings(strings.NewReader(test.config), actions), test.expectedError)
+			// nothing was set
+			assert.Len(t, g.keybindings, count)
+		})
+	}
+}
@@ -5,8 +5,6 @@
 package gocui
 
 import (
-	"strings"
-
 	"github.com/gdamore/tcell/v2"
 )
 
@@ -19,43 +17,30 @@ type Modifier tcell.ModMask
 
 // Keybidings are used to link a given key-press event with a handler.
 type keybinding struct {
+	// the name of the context the keybinding belongs to, if any
+	context  string
 	viewName string
 	key      Key
 	ch       rune
 	mod      Modifier
 	handler  func(*Gui, *View) error
+
+	// shown in the help view
+	category    string
+	description string
 }
 
 // Parse takes the input string and extracts the keybinding.
 // Returns a Key / rune, a Modifier and an error.
+//
+// The input is a key descriptor: a character or the name of a key, like
+// "Enter", "PgUp" or "F5", preceded by modifiers separated by '+', like
+// "Ctrl+Shift+Up" or "Alt+k". Names are case-insensitive, and the modifiers
+// can be written "Ctrl", "Alt", "Shift" or "Meta", among others. Keys that
+// terminals report as a single key, like Ctrl+A or Shift+Tab, are returned as
+// that key. Errors are of type *KeyParseError.
 func Parse(input string) (interface{}, Modifier, error) {
-	if len(input) == 1 {
-		_, r, err := getKey(rune(input[0]))
-		if err != nil {
-			return nil, ModNone, err
-		}
-		return r, ModNone, nil
-	}
-
-	var modifier Modifier
-	cleaned := make([]string, 0)
-
-	tokens := strings.Split(input, "+")
-	for _, t := range tokens {
-		normalized := strings.Title(strings.ToLower(t))
-		if t == "Alt" {
-			modifier = ModAlt
-			continue
-		}
-		cleaned = append(cleaned, normalized)
-	}
-
-	key, exist := translate[strings.Join(cleaned, "")]
-	if !exist {
-		return nil, ModNone, ErrNoSuchKeybind
-	}
-
-	return key, modifier, nil
+	return parseKeyDescriptor(input)
 }
 
 // ParseAll takes an array of strings and returns a map of all keybindings.
@@ -119,116 +104,131 @@ func eventMatchesKey(ev *GocuiEvent, key interface{}) bool {
 
 // matchKeypress returns if the keybinding matches the keypress.
 func (kb *keybinding) matchKeypress(key Key, ch rune, mod Modifier) bool {
-	return kb.key == key && kb.ch == ch && kb.mod == mod
+	if kb.key == key && kb.ch == ch && kb.mod == mod {
+		return true
+	}
+	// with extended keys, the keys that have placeholders are reported as
+	// they are, with their modifiers
+	kbKey, kbMod := extendedKey(kb.key, kb.mod)
+	return kbKey == key && kb.ch == ch && kbMod == mod
 }
 
-// translations for strings to keys
+// translations for strings to keys, still accepted by Parse for backward
+// compatibility
 var translate = map[string]Key{
-	"F1":             KeyF1,
-	"F2":             KeyF2,
-	"F3":             KeyF3,
-	"F4":             KeyF4,
-	"F5":             KeyF5,
-	"F6":             KeyF6,
-	"F7":             KeyF7,
-	"F8":             KeyF8,
-	"F9":             KeyF9,
-	"F10":            KeyF10,
-	"F11":            KeyF11,
-	"F12":            KeyF12,
-	"Insert":         KeyInsert,
-	"Delete":         KeyDelete,
-	"Home":           KeyHome,
-	"End":            KeyEnd,
-	"Pgup":           KeyPgup,
-	"Pgdn":           KeyPgdn,
-	"ArrowUp":        KeyArrowUp,
-	"ShiftArrowUp":   KeyShiftArrowUp,
-	"ArrowDown":      KeyArrowDown,
-	"ShiftArrowDown": KeyShiftArrowDown,
-	"ArrowLeft":      KeyArrowLeft,
-	"ArrowRight":     KeyArrowRight,
-	"CtrlTilde":      KeyCtrlTilde,
-	"Ctrl2":          KeyCtrl2,
-	"CtrlSpace":      KeyCtrlSpace,
-	"CtrlA":          KeyCtrlA,
-	"CtrlB":          KeyCtrlB,
-	"CtrlC":          KeyCtrlC,
-	"CtrlD":          KeyCtrlD,
-	"CtrlE":          KeyCtrlE,
-	"CtrlF":          KeyCtrlF,
-	"CtrlG":          KeyCtrlG,
-	"Backspace":      KeyBackspace,
-	"CtrlH":          KeyCtrlH,
-	"Tab":            KeyTab,
-	"BackTab":        KeyBacktab,
-	"CtrlI":          KeyCtrlI,
-	"CtrlJ":          KeyCtrlJ,
-	"CtrlK":          KeyCtrlK,
-	"CtrlL":          KeyCtrlL,
-	"Enter":          KeyEnter,
-	"CtrlM":          KeyCtrlM,
-	"CtrlN":          KeyCtrlN,
-	"CtrlO":          KeyCtrlO,
-	"CtrlP":          KeyCtrlP,
-	"CtrlQ":          KeyCtrlQ,
-	"CtrlR":          KeyCtrlR,
-	"CtrlS":          KeyCtrlS,
-	"CtrlT":          KeyCtrlT,
-	"CtrlU":          KeyCtrlU,
-	"CtrlV":          KeyCtrlV,
-	"CtrlW":          KeyCtrlW,
-	"CtrlX":          KeyCtrlX,
-	"CtrlY":          KeyCtrlY,
-	"CtrlZ":          KeyCtrlZ,
-	"Esc":            KeyEsc,
-	"CtrlLsqBracket": KeyCtrlLsqBracket,
-	"Ctrl3":          KeyCtrl3,
-	"Ctrl4":          KeyCtrl4,
-	"CtrlBackslash":  KeyCtrlBackslash,
-	"Ctrl5":          KeyCtrl5,
-	"CtrlRsqBracket": KeyCtrlRsqBracket,
-	"Ctrl6":          KeyCtrl6,
-	"Ctrl7":          KeyCtrl7,
-	"CtrlSlash":      KeyCtrlSlash,
-	"CtrlUnderscore": KeyCtrlUnderscore,
-	"Space":          KeySpace,
-	"Backspace2":     KeyBackspace2,
-	"Ctrl8":          KeyCtrl8,
-	"Mouseleft":      MouseLeft,
-	"Mousemiddle":    MouseMiddle,
-	"Mouseright":     MouseRight,
-	"Mouserelease":   MouseRelease,
-	"MousewheelUp":   MouseWheelUp,
-	"MousewheelDown": MouseWheelDown,
+	"F1":              KeyF1,
+	"F2":              KeyF2,
+	"F3":              KeyF3,
+	"F4":              KeyF4,
+	"F5":              KeyF5,
+	"F6":              KeyF6,
+	"F7":              KeyF7,
+	"F8":              KeyF8,
+	"F9":              KeyF9,
+	"F10":             KeyF10,
+	"F11":             KeyF11,
+	"F12":             KeyF12,
+	"Insert":          KeyInsert,
+	"Delete":          KeyDelete,
+	"Home":            KeyHome,
+	"End":             KeyEnd,
+	"Pgup":            KeyPgup,
+	"Pgdn":            KeyPgdn,
+	"ArrowUp":         KeyArrowUp,
+	"ShiftArrowUp":    KeyShiftArrowUp,
+	"ArrowDown":       KeyArrowDown,
+	"ShiftArrowDown":  KeyShiftArrowDown,
+	"ArrowLeft":       KeyArrowLeft,
+	"ShiftArrowLeft":  KeyShiftArrowLeft,
+	"ArrowRight":      KeyArrowRight,
+	"ShiftArrowRight": KeyShiftArrowRight,
+	"ShiftHome":       KeyShiftHome,
+	"ShiftEnd":        KeyShiftEnd,
+	"CtrlTilde":       KeyCtrlTilde,
+	"Ctrl2":           KeyCtrl2,
+	"CtrlSpace":       KeyCtrlSpace,
+	"CtrlA":           KeyCtrlA,
+	"CtrlB":           KeyCtrlB,
+	"CtrlC":           KeyCtrlC,
+	"CtrlD":           KeyCtrlD,
+	"CtrlE":           KeyCtrlE,
+	"CtrlF":           KeyCtrlF,
+	"CtrlG":           KeyCtrlG,
+	"Backspace":       KeyBackspace,
+	"CtrlH":           KeyCtrlH,
+	"Tab":             KeyTab,
+	"BackTab":         KeyBacktab,
+	"CtrlI":           KeyCtrlI,
+	"CtrlJ":           KeyCtrlJ,
+	"CtrlK":           KeyCtrlK,
+	"CtrlL":           KeyCtrlL,
+	"Enter":           KeyEnter,
+	"CtrlM":           KeyCtrlM,
+	"CtrlN":           KeyCtrlN,
+	"CtrlO":           KeyCtrlO,
+	"CtrlP":           KeyCtrlP,
+	"CtrlQ":           KeyCtrlQ,
+	"CtrlR":           KeyCtrlR,
+	"CtrlS":           KeyCtrlS,
+	"CtrlT":           KeyCtrlT,
+	"CtrlU":           KeyCtrlU,
+	"CtrlV":           KeyCtrlV,
+	"CtrlW":           KeyCtrlW,
+	"CtrlX":           KeyCtrlX,
+	"CtrlY":           KeyCtrlY,
+	"CtrlZ":           KeyCtrlZ,
+	"Esc":             KeyEsc,
+	"CtrlLsqBracket":  KeyCtrlLsqBracket,
+	"Ctrl3":           KeyCtrl3,
+	"Ctrl4":           KeyCtrl4,
+	"CtrlBackslash":   KeyCtrlBackslash,
+	"Ctrl5":           KeyCtrl5,
+	"CtrlRsqBracket":  KeyCtrlRsqBracket,
+	"Ctrl6":           KeyCtrl6,
+	"Ctrl7":           KeyCtrl7,
+	"CtrlSlash":       KeyCtrlSlash,
+	"CtrlUnderscore":  KeyCtrlUnderscore,
+	"Space":           KeySpace,
+	"Backspace2":      KeyBackspace2,
+	"Ctrl8":           KeyCtrl8,
+	"Mouseleft":       MouseLeft,
+	"Mousemiddle":     MouseMiddle,
+	"Mouseright":      MouseRight,
+	"Mouserelease":    MouseRelease,
+	"MousewheelUp":    MouseWheelUp,
+	"MousewheelDown":  MouseWheelDown,
 }
 
 // Special keys.
 const (
-	KeyF1             Key = Key(tcell.KeyF1)
-	KeyF2                 = Key(tcell.KeyF2)
-	KeyF3                 = Key(tcell.KeyF3)
-	KeyF4                 = Key(tcell.KeyF4)
-	KeyF5                 = Key(tcell.KeyF5)
-	KeyF6                 = Key(tcell.KeyF6)
-	KeyF7                 = Key(tcell.KeyF7)
-	KeyF8                 = Key(tcell.KeyF8)
-	KeyF9                 = Key(tcell.KeyF9)
-	KeyF10                = Key(tcell.KeyF10)
-	KeyF11                = Key(tcell.KeyF11)
-	KeyF12                = Key(tcell.KeyF12)
-	KeyInsert             = Key(tcell.KeyInsert)
-	KeyDelete             = Key(tcell.KeyDelete)
-	KeyHome               = Key(tcell.KeyHome)
-	KeyEnd                = Key(tcell.KeyEnd)
-	KeyPgdn               = Key(tcell.KeyPgDn)
-	KeyPgup               = Key(tcell.KeyPgUp)
-	KeyArrowUp            = Key(tcell.KeyUp)
-	KeyShiftArrowUp       = Key(tcell.KeyF62)
-	KeyArrowDown          = Key(tcell.KeyDown)
-	KeyShiftArrowDown     = Key(tcell.KeyF63)
-	KeyArrowLeft          = Key(tcell.KeyLeft)
-	KeyArrowRight         = Key(tcell.KeyRight)
+	KeyF1              Key = Key(tcell.KeyF1)
+	KeyF2                  = Key(tcell.KeyF2)
+	KeyF3                  = Key(tcell.KeyF3)
+	KeyF4                  = Key(tcell.KeyF4)
+	KeyF5                  = Key(tcell.KeyF5)
+	KeyF6                  = Key(tcell.KeyF6)
+	KeyF7                  = Key(tcell.KeyF7)
+	KeyF8                  = Key(tcell.KeyF8)
+	KeyF9                  = Key(tcell.KeyF9)
+	KeyF10                 = Key(tcell.KeyF10)
+	KeyF11                 = Key(tcell.KeyF11)
+	KeyF12                 = Key(tcell.KeyF12)
+	KeyInsert              = Key(tcell.KeyInsert)
+	KeyDelete              = Key(tcell.KeyDelete)
+	KeyHome                = Key(tcell.KeyHome)
+	KeyEnd                 = Key(tcell.KeyEnd)
+	KeyPgdn                = Key(tcell.KeyPgDn)
+	KeyPgup                = Key(tcell.KeyPgUp)
+	KeyArrowUp             = Key(tcell.KeyUp)
+	KeyShiftArrowUp        = Key(tcell.KeyF62)
+	KeyArrowDown           = Key(tcell.KeyDown)
+	KeyShiftArrowDown      = Key(tcell.KeyF63)
+	KeyArrowLeft           = Key(tcell.KeyLeft)
+	KeyShiftArrowLeft      = Key(tcell.KeyF55)
+	KeyArrowRight          = Key(tcell.KeyRight)
+	KeyShiftArrowRight     = Key(tcell.KeyF54)
+	KeyShiftHome           = Key(tcell.KeyF53)
+	KeyShiftEnd            = Key(tcell.KeyF52)
 )
 
 // Keys combinations.
@@ -298,9 +298,23 @@ const (
 
 // Modifiers.
 const (
-	ModNone   Modifier = Modifier(0)
-	ModAlt             = Modifier(tcell.ModAlt)
-	ModMotion          = Modifier(2) // just picking an arbitrary number here that doesn't clash with tcell.ModAlt
-	// ModCtrl doesn't work with keyboard keys. Use CtrlKey in Key and ModNone. This is was for mouse clicks only (tcell.v1)
-	// ModCtrl = Modifier(tcell.ModCtrl)
+	ModNone  Modifier = Modifier(0)
+	ModShift          = Modifier(tcell.ModShift)
+	// ModShift and ModCtrl are only reported for keys pressed together with
+	// other modifiers, for mouse events, and with extended keys (see
+	// NewGuiOpts.ExtendedKeys) for keys that don't include Ctrl themselves,
+	// like arrows and function keys. Use e.g. KeyCtrlA and ModNone for Ctrl+A.
+	ModCtrl = Modifier(tcell.ModCtrl)
+	ModAlt  = Modifier(tcell.ModAlt)
+	ModMeta = Modifier(tcell.ModMeta)
+	// ModMotion used to be 2, which is the value of ModCtrl; code that
+	// compares modifiers with the number rather than the constant must be
+	// updated.
+	ModMotion = Modifier(1 << 8) // just picking an arbitrary number here that doesn't clash with tcell's modifiers
+	// ModRepeat and ModRelease make a keybinding fire when the key is
+	// repeated because it's held down, or when it's released, instead of when
+	// it's pressed. Only terminals supporting extended keys report those
+	// events, see NewGuiOpts.ExtendedKeys.
+	ModRepeat  = Modifier(1 << 9)
+	ModRelease = Modifier(1 << 10)
 )
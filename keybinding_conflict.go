@@ -0,0 +1,206 @@
+package gocui
+
+import (
+	"fmt"
+)
+
+// ConflictKind tells how a keybinding conflicts with another one.
+type ConflictKind int
+
+const (
+	// ConflictDuplicate is a keybinding registered twice for the same view,
+	// context and keys. Only the first one ever fires.
+	ConflictDuplicate ConflictKind = iota
+	// ConflictShadowsParent is a keybinding of a view that hides the one of
+	// its parent view for the same keys.
+	ConflictShadowsParent
+	// ConflictShadowsGlobal is a keybinding of a view that hides the global
+	// one for the same keys while the view has the focus.
+	ConflictShadowsGlobal
+	// ConflictBlacklisted is a keybinding whose key was blacklisted after it
+	// was registered, so that it never fires.
+	ConflictBlacklisted
+)
+
+func (k ConflictKind) String() string {
+	switch k {
+	case ConflictDuplicate:
+		return "duplicate"
+	case ConflictShadowsParent:
+		return "shadows parent view"
+	case ConflictShadowsGlobal:
+		return "shadows global"
+	case ConflictBlacklisted:
+		return "blacklisted"
+	default:
+		return "unknown"
+	}
+}
+
+// KeybindingConflictError describes a keybinding that conflicts with another
+// one, or with the blacklist.
+type KeybindingConflictError struct {
+	Kind ConflictKind
+	// ViewName is the view of the keybinding, or "" for a global one.
+	ViewName string
+	// Context is the context of the keybinding, if any.
+	Context string
+	// Keys is the key descriptor of the keybinding, like "Ctrl+C".
+	Keys string
+	// OtherViewName is the view of the keybinding it conflicts with, if any.
+	OtherViewName string
+}
+
+func (e *KeybindingConflictError) Error() string {
+	where := "global keybinding"
+	if e.ViewName != "" {
+		where = fmt.Sprintf("keybinding of view %q", e.ViewName)
+	}
+	if e.Context != "" {
+		where += fmt.Sprintf(" in context %q", e.Context)
+	}
+
+	switch e.Kind {
+	case ConflictDuplicate:
+		return fmt.Sprintf("%s %s is already registered", where, e.Keys)
+	case ConflictShadowsParent:
+		return fmt.Sprintf("%s %s shadows the one of parent view %q", where, e.Keys, e.OtherViewName)
+	case ConflictShadowsGlobal:
+		return fmt.Sprintf("%s %s shadows a global keybinding", where, e.Keys)
+	default:
+		return fmt.Sprintf("%s %s is blacklisted", where, e.Keys)
+	}
+}
+
+// addKeybinding registers a keybinding, checking that it isn't a duplicate.
+func (g *Gui) addKeybinding(kb *keybinding) error {
+	for _, other := range g.keybindings {
+		if other.context == kb.context && other.viewName == kb.viewName && other.matchKeypress(kb.key, kb.ch, kb.mod) {
+			conflict := &KeybindingConflictError{
+				Kind:     ConflictDuplicate,
+				ViewName: kb.viewName,
+				Context:  kb.context,
+				Keys:     Format(keybindingKey(kb.key, kb.ch), kb.mod),
+			}
+			if err := g.reportConflict(conflict); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	g.keybindings = append(g.keybindings, kb)
+	return nil
+}
+
+// addSequenceBinding registers a sequence keybinding, checking that it isn't
+// a duplicate.
+func (g *Gui) addSequenceBinding(sb *sequenceBinding) error {
+	for _, other := range g.sequenceBindings {
+		if other.viewName == sb.viewName && len(other.presses) == len(sb.presses) && keyPressesEqual(other.presses, sb.presses) {
+			conflict := &KeybindingConflictError{
+				Kind:     ConflictDuplicate,
+				ViewName: sb.viewName,
+				Keys:     sb.keysString(),
+			}
+			if err := g.reportConflict(conflict); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	g.sequenceBindings = append(g.sequenceBindings, sb)
+	return nil
+}
+
+// reportConflict passes a conflict found when registering a keybinding to
+// OnKeybindingConflict, and returns it if StrictKeybindings is set.
+func (g *Gui) reportConflict(conflict *KeybindingConflictError) error {
+	if g.OnKeybindingConflict != nil {
+		g.OnKeybindingConflict(conflict)
+	}
+	if g.StrictKeybindings {
+		return conflict
+	}
+	return nil
+}
+
+// ValidateKeybindings returns the conflicts among the registered keybindings:
+// duplicates, keybindings of views shadowing those of their parent views or
+// global ones, and keybindings of blacklisted keys.
+func (g *Gui) ValidateKeybindings() []*KeybindingConflictError {
+	conflicts := []*KeybindingConflictError{}
+	add := func(kind ConflictKind, kb *keybinding, otherViewName string) {
+		conflicts = append(conflicts, &KeybindingConflictError{
+			Kind:          kind,
+			ViewName:      kb.viewName,
+			Context:       kb.context,
+			Keys:          Format(keybindingKey(kb.key, kb.ch), kb.mod),
+			OtherViewName: otherViewName,
+		})
+	}
+
+	for i, kb := range g.keybindings {
+		if g.isBlacklisted(kb.key) {
+			add(ConflictBlacklisted, kb, "")
+		}
+
+		for _, other := range g.keybindings[:i] {
+			if other.context != kb.context || !other.matchKeypress(kb.key, kb.ch, kb.mod) {
+				continue
+			}
+			if other.viewName == kb.viewName {
+				add(ConflictDuplicate, kb, "")
+				break
+			}
+		}
+
+		if kb.viewName == "" {
+			continue
+		}
+		v, err := g.View(kb.viewName)
+		for _, other := range g.keybindings {
+			if other == kb || other.context != kb.context || !other.matchKeypress(kb.key, kb.ch, kb.mod) {
+				continue
+			}
+			if err == nil && v.ParentView != nil && other.viewName == v.ParentView.name {
+				add(ConflictShadowsParent, kb, other.viewName)
+			}
+			if other.viewName == "" {
+				add(ConflictShadowsGlobal, kb, "")
+			}
+		}
+	}
+
+	for i, sb := range g.sequenceBindings {
+		for _, other := range g.sequenceBindings[:i] {
+			if other.viewName == sb.viewName && len(other.presses) == len(sb.presses) && keyPressesEqual(other.presses, sb.presses) {
+				conflicts = append(conflicts, &KeybindingConflictError{
+					Kind:     ConflictDuplicate,
+					ViewName: sb.viewName,
+					Keys:     sb.keysString(),
+				})
+				break
+			}
+		}
+	}
+
+	return conflicts
+}
+
+// keybindingKey returns the rune of a keybinding, or its key if it has none.
+func keybindingKey(key Key, ch rune) interface{} {
+	if ch != 0 {
+		return ch
+	}
+	return key
+}
+
+func (sb *sequenceBinding) keysString() string {
+	keys := make([]KeyPress, len(sb.presses))
+	for i, press := range sb.presses {
+		keys[i] = KeyPress{Key: keybindingKey(press.key, press.ch), Mod: press.mod}
+	}
+	return FormatSequence(keys)
+}
@@ -0,0 +1,61 @@
+package gocui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeybindingConflicts(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 40, Height: 20})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	parent, _ := g.SetView("parent", 0, 0, 10, 10, 0)
+	files, _ := g.SetView("files", 0, 0, 10, 10, 0)
+	files.ParentView = parent
+
+	reported := []string{}
+	g.OnKeybindingConflict = func(err *KeybindingConflictError) {
+		reported = append(reported, err.Error())
+	}
+
+	noop := func(*Gui, *View) error { return nil }
+	assert.NoError(t, g.SetKeybinding("files", 'd', ModNone, noop))
+	// duplicates are reported, but still registered unless strict
+	assert.NoError(t, g.SetKeybinding("files", 'd', ModNone, noop))
+	assert.Len(t, g.keybindings, 2)
+	// the same keys in another context or view are fine
+	assert.NoError(t, g.SetContextKeybinding("staging", "files", 'd', ModNone, noop))
+	assert.NoError(t, g.SetKeybinding("parent", 'd', ModNone, noop))
+	assert.NoError(t, g.SetKeybinding("", KeyCtrlC, ModNone, noop))
+	assert.NoError(t, g.SetKeybinding("files", KeyCtrlC, ModNone, noop))
+	assert.NoError(t, g.SetSequenceKeybinding("files", []KeyPress{{Key: 'g'}, {Key: 'g'}}, noop))
+	assert.NoError(t, g.SetSequenceKeybinding("files", []KeyPress{{Key: 'g'}, {Key: 'g'}}, noop))
+	assert.EqualValues(t, []string{
+		`keybinding of view "files" d is already registered`,
+		`keybinding of view "files" g g is already registered`,
+	}, reported)
+
+	g.StrictKeybindings = true
+	err = g.SetContextKeybinding("staging", "files", 'd', ModNone, noop)
+	assert.EqualError(t, err, `keybinding of view "files" in context "staging" d is already registered`)
+	assert.EqualValues(t, ConflictDuplicate, err.(*KeybindingConflictError).Kind)
+	assert.Len(t, g.keybindings, 6)
+
+	assert.NoError(t, g.SetKeybinding("files", KeyF5, ModShift, noop))
+	assert.NoError(t, g.BlacklistKeybinding(KeyF5))
+
+	messages := []string{}
+	for _, conflict := range g.ValidateKeybindings() {
+		messages = append(messages, conflict.Kind.String()+": "+conflict.Error())
+	}
+	assert.EqualValues(t, []string{
+		`shadows parent view: keybinding of view "files" d shadows the one of parent view "parent"`,
+		`duplicate: keybinding of view "files" d is already registered`,
+		`shadows parent view: keybinding of view "files" d shadows the one of parent view "parent"`,
+		`shadows global: keybinding of view "files" Ctrl+C shadows a global keybinding`,
+		`blacklisted: keybinding of view "files" Shift+F5 is blacklisted`,
+		`duplicate: keybinding of view "files" g g is already registered`,
+	}, messages)
+}
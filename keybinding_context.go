@@ -0,0 +1,103 @@
+package gocui
+
+// KeybindingContext is a named set of keybindings, e.g. "staging" or
+// "search", that is only active while it's on the context stack of the Gui.
+// Keybindings of the contexts higher in the stack take precedence over those
+// of the contexts beneath them, which take precedence over the keybindings
+// that belong to no context.
+type KeybindingContext struct {
+	Name string
+	// DisableGlobals, if true, disables the global keybindings, i.e. those
+	// that apply to all views, of the contexts beneath this one and of no
+	// context while this one is active. Its own global keybindings still
+	// work.
+	DisableGlobals bool
+}
+
+// PushContext activates a context, on top of the active ones.
+func (g *Gui) PushContext(ctx *KeybindingContext) {
+	g.contexts = append(g.contexts, ctx)
+}
+
+// PopContext deactivates the topmost context and returns it, or nil if no
+// context is active.
+func (g *Gui) PopContext() *KeybindingContext {
+	if len(g.contexts) == 0 {
+		return nil
+	}
+
+	ctx := g.contexts[len(g.contexts)-1]
+	g.contexts = g.contexts[:len(g.contexts)-1]
+	return ctx
+}
+
+// RemoveContext deactivates the context with the given name, wherever it is
+// in the stack.
+func (g *Gui) RemoveContext(name string) {
+	contexts := g.contexts[:0]
+	for _, ctx := range g.contexts {
+		if ctx.Name != name {
+			contexts = append(contexts, ctx)
+		}
+	}
+	g.contexts = contexts
+}
+
+// CurrentContext returns the topmost context, or nil if no context is
+// active.
+func (g *Gui) CurrentContext() *KeybindingContext {
+	if len(g.contexts) == 0 {
+		return nil
+	}
+
+	return g.contexts[len(g.contexts)-1]
+}
+
+// SetContextKeybinding creates a keybinding like SetKeybinding, which is only
+// active while the given context is.
+func (g *Gui) SetContextKeybinding(context string, viewname string, key interface{}, mod Modifier, handler func(*Gui, *View) error) error {
+	k, ch, err := getKey(key)
+	if err != nil {
+		return err
+	}
+
+	if g.isBlacklisted(k) {
+		return ErrBlacklisted
+	}
+
+	kb := newKeybinding(viewname, k, ch, mod, handler)
+	kb.context = context
+	return g.addKeybinding(kb)
+}
+
+// DeleteContextKeybindings deletes all keybindings of a context.
+func (g *Gui) DeleteContextKeybindings(context string) {
+	var s []*keybinding
+	for _, kb := range g.keybindings {
+		if kb.context != context {
+			s = append(s, kb)
+		}
+	}
+	g.keybindings = s
+}
+
+// contextRanks returns the precedence of the keybindings of each active
+// context, lower being higher precedence, with the keybindings of no context
+// last. It also returns the lowest precedence at which global keybindings are
+// enabled.
+func (g *Gui) contextRanks() (map[string]int, int) {
+	ranks := map[string]int{"": len(g.contexts)}
+	globalsUpTo := len(g.contexts)
+	for i := len(g.contexts) - 1; i >= 0; i-- {
+		ctx := g.contexts[i]
+		rank := len(g.contexts) - 1 - i
+		if _, ok := ranks[ctx.Name]; !ok {
+			ranks[ctx.Name] = rank
+		}
+		if ctx.DisableGlobals && rank < globalsUpTo {
+			globalsUpTo = rank
+		}
+	}
+
+	return ranks, globalsUpTo
+}
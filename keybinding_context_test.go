@@ -0,0 +1,115 @@
+package gocui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeybindingContexts(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 40, Height: 20})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	_, _ = g.SetView("files", 0, 0, 10, 10, 0)
+	_, _ = g.SetCurrentView("files")
+
+	pressed := ""
+	press := func(name string) func(*Gui, *View) error {
+		return func(*Gui, *View) error {
+			pressed = name
+			return nil
+		}
+	}
+	assert.NoError(t, g.SetKeybinding("", 'q', ModNone, press("quit")))
+	assert.NoError(t, g.SetKeybinding("files", 'a', ModNone, press("add")))
+	assert.NoError(t, g.SetContextKeybinding("staging", "files", 'a', ModNone, press("stage")))
+	assert.NoError(t, g.SetContextKeybinding("staging", "", 'u', ModNone, press("unstage all")))
+	assert.NoError(t, g.SetContextKeybinding("search", "", 'a', ModNone, press("search all")))
+	assert.NoError(t, g.SetContextKeybinding("search", "", 'n', ModNone, press("next match")))
+
+	tests := []struct {
+		name     string
+		contexts []*KeybindingContext
+		ch       rune
+		expected string
+	}{
+		{name: "no context", ch: 'a', expected: "add"},
+		{name: "inactive context", ch: 'u', expected: ""},
+		{name: "context shadows a binding", contexts: []*KeybindingContext{{Name: "staging"}}, ch: 'a', expected: "stage"},
+		{name: "context binding", contexts: []*KeybindingContext{{Name: "staging"}}, ch: 'u', expected: "unstage all"},
+		{name: "globals are enabled", contexts: []*KeybindingContext{{Name: "staging"}}, ch: 'q', expected: "quit"},
+		{name: "view bindings beat globals", contexts: []*KeybindingContext{{Name: "staging"}, {Name: "search"}}, ch: 'a', expected: "stage"},
+		{name: "globals are disabled", contexts: []*KeybindingContext{{Name: "staging"}, {Name: "search", DisableGlobals: true}}, ch: 'q', expected: ""},
+		{name: "globals beneath are disabled", contexts: []*KeybindingContext{{Name: "staging"}, {Name: "search", DisableGlobals: true}}, ch: 'u', expected: ""},
+		{name: "own globals stay enabled", contexts: []*KeybindingContext{{Name: "staging"}, {Name: "search", DisableGlobals: true}}, ch: 'n', expected: "next match"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, ctx := range test.contexts {
+				g.PushContext(ctx)
+			}
+			defer func() {
+				for g.PopContext() != nil {
+				}
+			}()
+
+			pressed = ""
+			assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Ch: test.ch}))
+			assert.EqualValues(t, test.expected, pressed)
+		})
+	}
+
+	g.PushContext(&KeybindingContext{Name: "staging"})
+	g.PushContext(&KeybindingContext{Name: "search"})
+	g.RemoveContext("staging")
+	assert.EqualValues(t, "search", g.CurrentContext().Name)
+	assert.EqualValues(t, "search", g.PopContext().Name)
+	assert.Nil(t, g.CurrentContext())
+
+	g.DeleteContextKeybindings("staging")
+	g.PushContext(&KeybindingContext{Name: "staging"})
+	pressed = ""
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Ch: 'a'}))
+	assert.EqualValues(t, "add", pressed)
+}
+
+func TestKeybindingRegistrationOrder(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 40, Height: 20})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	panel, _ := g.SetView("panel", 0, 0, 20, 10, 0)
+	files, _ := g.SetView("files", 1, 1, 10, 5, 0)
+	files.ParentView = panel
+	_, _ = g.SetCurrentView("files")
+
+	pressed := ""
+	press := func(name string) func(*Gui, *View) error {
+		return func(*Gui, *View) error {
+			pressed = name
+			return nil
+		}
+	}
+	// with equal context ranks, the first binding registered wins, whether
+	// it's a binding of the view, of its parent or a global one
+	for _, viewName := range []string{"files", "panel", ""} {
+		label := viewName
+		if label == "" {
+			label = "global"
+		}
+		for _, name := range []string{"first", "second"} {
+			assert.NoError(t, g.SetKeybinding(viewName, 'x', ModNone, press(label+" "+name)))
+		}
+	}
+
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Ch: 'x'}))
+	assert.Equal(t, "files first", pressed)
+	g.DeleteViewKeybindings("files")
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Ch: 'x'}))
+	assert.Equal(t, "panel first", pressed)
+	g.DeleteViewKeybindings("panel")
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Ch: 'x'}))
+	assert.Equal(t, "global first", pressed)
+}
@@ -0,0 +1,404 @@
+package gocui
+
+// sizeKind tells how the size of a Box is determined.
+type sizeKind int
+
+const (
+	sizeFlex sizeKind = iota
+	sizeFixed
+	sizePercent
+)
+
+// Size is the size of a Box along the direction of its container, i.e. its
+// width in a row and its height in a column.
+type Size struct {
+	kind  sizeKind
+	value int
+
+	// Min and Max bound the size. Zero means no bound.
+	Min int
+	Max int
+}
+
+// Fixed returns the Size of a box that is n cells wide or high.
+func Fixed(n int) Size {
+	return Size{kind: sizeFixed, value: n}
+}
+
+// Percent returns the Size of a box that takes up the given percentage of
+// the space of its container, not counting gutters.
+func Percent(percent int) Size {
+	return Size{kind: sizePercent, value: percent}
+}
+
+// Flex returns the Size of a box that shares the space left over by its
+// fixed and percentage sized siblings with its other flexible siblings, in
+// proportion to weight.
+func Flex(weight int) Size {
+	return Size{kind: sizeFlex, value: weight}
+}
+
+// WithMin returns a copy of the size that is at least n cells.
+func (s Size) WithMin(n int) Size {
+	s.Min = n
+	return s
+}
+
+// WithMax returns a copy of the size that is at most n cells.
+func (s Size) WithMax(n int) Size {
+	s.Max = n
+	return s
+}
+
+func (s Size) clamp(n int) int {
+	if s.Max > 0 && n > s.Max {
+		n = s.Max
+	}
+	if n < s.Min {
+		n = s.Min
+	}
+
+	return max(n, 0)
+}
+
+// LayoutDirection is the direction in which a container lays out its
+// children.
+type LayoutDirection int
+
+const (
+	// LayoutRow places the children side by side, from left to right.
+	LayoutRow LayoutDirection = iota
+	// LayoutColumn stacks the children, from top to bottom.
+	LayoutColumn
+)
+
+// Box is a node of a layout: either a view or a container of other boxes.
+// Boxes fill the whole space of their container across its direction, e.g.
+// the boxes of a row are as high as the row.
+type Box struct {
+	// View is the name of the view placed in the box. It's empty for
+	// containers.
+	View string
+	// OnCreate, if set, is called with the view when it is created, to
+	// initialize it.
+	OnCreate func(*View) error
+
+	Size Size
+
+	Direction LayoutDirection
+	Children  []*Box
+	// Gutter is the number of cells left empty between the children.
+	Gutter int
+
+	// Split makes a container of two children resizable: they share an edge,
+	// the divider, which the user can drag with the mouse. The first child
+	// takes up Ratio of the space, within the bounds of the Min of the
+	// children's sizes; their sizes are otherwise ignored.
+	Split bool
+	Ratio float64
+
+	// where the box was placed by the last layout pass
+	placed rect
+}
+
+// ViewBox returns a Box holding the view with the given name.
+func ViewBox(name string, size Size) *Box {
+	return &Box{View: name, Size: size}
+}
+
+// Row returns a container placing its children side by side.
+func Row(size Size, children ...*Box) *Box {
+	return &Box{Size: size, Direction: LayoutRow, Children: children}
+}
+
+// Column returns a container stacking its children from top to bottom.
+func Column(size Size, children ...*Box) *Box {
+	return &Box{Size: size, Direction: LayoutColumn, Children: children}
+}
+
+// SplitRow returns a split placing its children side by side, with the first
+// one taking up the given ratio of the width.
+func SplitRow(size Size, ratio float64, first *Box, second *Box) *Box {
+	return &Box{Size: size, Direction: LayoutRow, Children: []*Box{first, second}, Split: true, Ratio: ratio}
+}
+
+// SplitColumn returns a split stacking its children, with the first one
+// taking up the given ratio of the height.
+func SplitColumn(size Size, ratio float64, first *Box, second *Box) *Box {
+	return &Box{Size: size, Direction: LayoutColumn, Children: []*Box{first, second}, Split: true, Ratio: ratio}
+}
+
+// WithGutter sets the gutter of a container and returns it.
+func (b *Box) WithGutter(gutter int) *Box {
+	b.Gutter = gutter
+	return b
+}
+
+// Layout is a Manager that places views on the screen as described by a tree
+// of boxes. The root box takes up the whole screen, so the views are resized
+// along with the terminal:
+//
+//	g.SetManager(gocui.NewLayout(
+//		gocui.Column(gocui.Flex(1),
+//			gocui.Row(gocui.Flex(1),
+//				gocui.ViewBox("side", gocui.Percent(20).WithMin(10)),
+//				gocui.ViewBox("main", gocui.Flex(1)),
+//			),
+//			gocui.ViewBox("cmdline", gocui.Fixed(3)),
+//		),
+//	))
+//
+// The dividers of splits can be dragged with the mouse, and moved from
+// keybindings with Box.MoveDivider. The ratios are kept in the boxes, so the
+// following layout passes honor them.
+type Layout struct {
+	Root *Box
+
+	// the splits placed by the last layout pass, and the one whose divider is
+	// being dragged
+	splits   []*Box
+	dragging *Box
+}
+
+func NewLayout(root *Box) *Layout {
+	return &Layout{Root: root}
+}
+
+// Layout places the views of the layout with SetView.
+func (l *Layout) Layout(g *Gui) error {
+	maxX, maxY := g.Size()
+	l.splits = l.splits[:0]
+	return l.Root.place(g, l, rect{x0: 0, y0: 0, x1: maxX, y1: maxY})
+}
+
+// rect is an area of the screen, excluding x1 and y1.
+type rect struct {
+	x0, y0, x1, y1 int
+}
+
+func (b *Box) place(g *Gui, l *Layout, r rect) error {
+	b.placed = r
+
+	if b.View != "" {
+		// views include their bottom-right corner
+		v, err := g.SetView(b.View, r.x0, r.y0, max(r.x0, r.x1-1), max(r.y0, r.y1-1), 0)
+		if err != nil {
+			if !IsUnknownView(err) {
+				return err
+			}
+			if b.OnCreate != nil {
+				if err := b.OnCreate(v); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if b.Split && len(b.Children) == 2 {
+		l.splits = append(l.splits, b)
+		first, second := r, r
+		if b.Direction == LayoutColumn {
+			first.y1 = r.y0 + b.dividerOffset() + 1
+			second.y0 = first.y1 - 1
+		} else {
+			first.x1 = r.x0 + b.dividerOffset() + 1
+			second.x0 = first.x1 - 1
+		}
+		if err := b.Children[0].place(g, l, first); err != nil {
+			return err
+		}
+		return b.Children[1].place(g, l, second)
+	}
+
+	pos := 0
+	for i, length := range b.childLengths(b.length()) {
+		child := r
+		if b.Direction == LayoutColumn {
+			child.y0 = r.y0 + pos
+			child.y1 = child.y0 + length
+		} else {
+			child.x0 = r.x0 + pos
+			child.x1 = child.x0 + length
+		}
+		if err := b.Children[i].place(g, l, child); err != nil {
+			return err
+		}
+		pos += length + b.Gutter
+	}
+
+	return nil
+}
+
+// childLengths divides the given length among the children.
+func (b *Box) childLengths(length int) []int {
+	if len(b.Children) == 0 {
+		return nil
+	}
+
+	available := max(0, length-b.Gutter*(len(b.Children)-1))
+	lengths := make([]int, len(b.Children))
+	flexible := []int{}
+	remaining := available
+	for i, child := range b.Children {
+		switch child.Size.kind {
+		case sizeFixed:
+			lengths[i] = child.Size.clamp(child.Size.value)
+		case sizePercent:
+			lengths[i] = child.Size.clamp(available * child.Size.value / 100)
+		default:
+			flexible = append(flexible, i)
+			continue
+		}
+		remaining -= lengths[i]
+	}
+
+	// share what's left among the flexible children. Those whose share
+	// violates their bounds are clamped and the rest is shared again among
+	// the others.
+	for len(flexible) > 0 {
+		totalWeight := 0
+		for _, i := range flexible {
+			totalWeight += max(1, b.Children[i].Size.value)
+		}
+
+		space := max(0, remaining)
+		unclamped := []int{}
+		for _, i := range flexible {
+			size := b.Children[i].Size
+			share := space * max(1, size.value) / totalWeight
+			if clamped := size.clamp(share); clamped != share {
+				lengths[i] = clamped
+				remaining -= clamped
+			} else {
+				unclamped = append(unclamped, i)
+			}
+		}
+
+		if len(unclamped) == len(flexible) {
+			// nothing was clamped; hand out the cells lost to rounding to the
+			// first children
+			distributed := 0
+			for _, i := range flexible {
+				lengths[i] = space * max(1, b.Children[i].Size.value) / totalWeight
+				distributed += lengths[i]
+			}
+			for j := 0; distributed < space; j++ {
+				lengths[flexible[j%len(flexible)]]++
+				distributed++
+			}
+			break
+		}
+		flexible = unclamped
+	}
+
+	// when the children don't fit, e.g. because their fixed sizes add up to
+	// more than the available space, they're cut short from the last one
+	left := available
+	for i := range lengths {
+		lengths[i] = min(lengths[i], left)
+		left -= lengths[i]
+	}
+
+	return lengths
+}
+
+// length returns the size of the box along its direction in the last layout
+// pass.
+func (b *Box) length() int {
+	if b.Direction == LayoutColumn {
+		return b.placed.y1 - b.placed.y0
+	}
+
+	return b.placed.x1 - b.placed.x0
+}
+
+// dividerOffset returns the position of the divider of a split relative to
+// the start of the split. The children share that row or column, which is
+// why the divider ranges over one cell less than the length of the split.
+func (b *Box) dividerOffset() int {
+	span := b.length() - 1
+	offset := int(b.Ratio*float64(span) + 0.5)
+	offset = min(offset, span-b.Children[1].Size.Min)
+	offset = max(offset, b.Children[0].Size.Min)
+
+	return max(0, min(offset, span))
+}
+
+// setDividerOffset moves the divider of a split to the given position
+// relative to the start of the split.
+func (b *Box) setDividerOffset(offset int) {
+	span := b.length() - 1
+	if span <= 0 {
+		return
+	}
+
+	b.Ratio = float64(max(0, min(offset, span))) / float64(span)
+}
+
+// MoveDivider moves the divider of a split by the given number of cells,
+// e.g. from a keybinding. The views are resized on the next layout pass.
+func (b *Box) MoveDivider(cells int) {
+	if !b.Split || len(b.Children) != 2 {
+		return
+	}
+
+	b.setDividerOffset(b.dividerOffset() + cells)
+}
+
+// onViewTitle tells whether the point (x, y) is on the title or the tabs of
+// a visible view.
+func (g *Gui) onViewTitle(x, y int) bool {
+	for _, v := range g.views {
+		if v.Visible && v.onTitle(x, y) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// onMouse lets the user drag the dividers of the splits.
+func (l *Layout) onMouse(g *Gui, ev *GocuiEvent) (bool, error) {
+	if l.dragging != nil {
+		if ev.Type == eventMouse && ev.Key == MouseLeft && ev.Mod&ModMotion != 0 {
+			split := l.dragging
+			if split.Direction == LayoutColumn {
+				split.setDividerOffset(ev.MouseY - split.placed.y0)
+			} else {
+				split.setDividerOffset(ev.MouseX - split.placed.x0)
+			}
+			return true, nil
+		}
+
+		// the button was released
+		l.dragging = nil
+		return ev.Type == eventMouse, nil
+	}
+
+	// presses carry the modifiers held, drags ModMotion as well
+	if ev.Type != eventMouse || ev.Key != MouseLeft || ev.Mod&ModMotion != 0 {
+		return false, nil
+	}
+
+	// nested splits are placed after their parents, so we look at those
+	// first
+	for i := len(l.splits) - 1; i >= 0; i-- {
+		split := l.splits[i]
+		r := split.placed
+		onDivider := false
+		if split.Direction == LayoutColumn {
+			onDivider = ev.MouseY == r.y0+split.dividerOffset() && ev.MouseX >= r.x0 && ev.MouseX < r.x1
+		} else {
+			onDivider = ev.MouseX == r.x0+split.dividerOffset() && ev.MouseY >= r.y0 && ev.MouseY < r.y1
+		}
+		// the divider of a column split is the top edge of the frame of
+		// the second view, where a click on its title or tabs is left to
+		// the view
+		if onDivider && !g.onViewTitle(ev.MouseX, ev.MouseY) {
+			l.dragging = split
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
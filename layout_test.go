@@ -0,0 +1,237 @@
+package gocui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayoutChildLengths(t *testing.T) {
+	tests := []struct {
+		name            string
+		sizes           []Size
+		gutter          int
+		length          int
+		expectedLengths []int
+	}{
+		{
+			name:            "fixed and flex",
+			sizes:           []Size{Fixed(10), Flex(1)},
+			length:          50,
+			expectedLengths: []int{10, 40},
+		},
+		{
+			name:            "percent",
+			sizes:           []Size{Percent(20), Flex(1)},
+			length:          50,
+			expectedLengths: []int{10, 40},
+		},
+		{
+			name:            "flex weights",
+			sizes:           []Size{Flex(1), Flex(2)},
+			length:          30,
+			expectedLengths: []int{10, 20},
+		},
+		{
+			name:            "rounding",
+			sizes:           []Size{Flex(1), Flex(1), Flex(1)},
+			length:          11,
+			expectedLengths: []int{4, 4, 3},
+		},
+		{
+			name:            "gutter",
+			sizes:           []Size{Flex(1), Flex(1)},
+			gutter:          2,
+			length:          22,
+			expectedLengths: []int{10, 10},
+		},
+		{
+			name:            "min",
+			sizes:           []Size{Percent(10).WithMin(8), Flex(1)},
+			length:          50,
+			expectedLengths: []int{8, 42},
+		},
+		{
+			name:            "flex max",
+			sizes:           []Size{Flex(1).WithMax(5), Flex(1)},
+			length:          30,
+			expectedLengths: []int{5, 25},
+		},
+		{
+			name:            "flex min",
+			sizes:           []Size{Flex(1).WithMin(20), Flex(1)},
+			length:          30,
+			expectedLengths: []int{20, 10},
+		},
+		{
+			name:            "not enough space",
+			sizes:           []Size{Fixed(20), Fixed(20), Flex(1)},
+			length:          30,
+			expectedLengths: []int{20, 10, 0},
+		},
+		{
+			name:            "overfull with percent",
+			sizes:           []Size{Fixed(20), Percent(50), Flex(1).WithMin(5)},
+			length:          30,
+			expectedLengths: []int{20, 10, 0},
+		},
+		{
+			name:            "overfull with gutter",
+			sizes:           []Size{Fixed(10), Fixed(10)},
+			gutter:          2,
+			length:          15,
+			expectedLengths: []int{10, 3},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			box := Row(Flex(1)).WithGutter(test.gutter)
+			for _, size := range test.sizes {
+				box.Children = append(box.Children, ViewBox("", size))
+			}
+			assert.EqualValues(t, test.expectedLengths, box.childLengths(test.length))
+		})
+	}
+}
+
+func TestLayout(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 80, Height: 24})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	created := []string{}
+	onCreate := func(v *View) error {
+		created = append(created, v.Name())
+		return nil
+	}
+	side := ViewBox("side", Percent(25))
+	side.OnCreate = onCreate
+	main := ViewBox("main", Flex(1))
+	main.OnCreate = onCreate
+	layout := NewLayout(Column(Flex(1),
+		Row(Flex(1), side, main).WithGutter(1),
+		ViewBox("cmdline", Fixed(3)),
+	))
+
+	assert.NoError(t, layout.Layout(g))
+	assert.EqualValues(t, []string{"side", "main"}, created)
+
+	assertPosition := func(name string, x0, y0, x1, y1 int) {
+		ax0, ay0, ax1, ay1, err := g.ViewPosition(name)
+		assert.NoError(t, err)
+		assert.EqualValues(t, []int{x0, y0, x1, y1}, []int{ax0, ay0, ax1, ay1}, name)
+	}
+	assertPosition("side", 0, 0, 18, 20)
+	assertPosition("main", 20, 0, 79, 20)
+	assertPosition("cmdline", 0, 21, 79, 23)
+
+	// views are only created once, and follow the size of the screen
+	g.maxX, g.maxY = 40, 10
+	assert.NoError(t, layout.Layout(g))
+	assert.EqualValues(t, []string{"side", "main"}, created)
+	assertPosition("side", 0, 0, 8, 6)
+	assertPosition("main", 10, 0, 39, 6)
+	assertPosition("cmdline", 0, 7, 39, 9)
+}
+
+func TestSplit(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 41, Height: 21})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	inner := SplitColumn(Flex(1), 0.5, ViewBox("top", Flex(1)), ViewBox("bottom", Flex(1)))
+	outer := SplitRow(Flex(1), 0.25, ViewBox("side", Flex(1).WithMin(5)), inner)
+	layout := NewLayout(outer)
+	g.SetManager(layout)
+	assert.NoError(t, layout.Layout(g))
+
+	assertPosition := func(name string, x0, y0, x1, y1 int) {
+		ax0, ay0, ax1, ay1, err := g.ViewPosition(name)
+		assert.NoError(t, err)
+		assert.EqualValues(t, []int{x0, y0, x1, y1}, []int{ax0, ay0, ax1, ay1}, name)
+	}
+	// the children of a split share the divider
+	assertPosition("side", 0, 0, 10, 20)
+	assertPosition("top", 10, 0, 40, 10)
+	assertPosition("bottom", 10, 10, 40, 20)
+
+	mouse := func(key Key, mod Modifier, x, y int) {
+		assert.NoError(t, g.onKey(&GocuiEvent{Type: eventMouse, Key: key, Mod: mod, MouseX: x, MouseY: y}))
+		assert.NoError(t, layout.Layout(g))
+	}
+
+	// dragging the divider of the nested split
+	mouse(MouseLeft, ModNone, 20, 10)
+	mouse(MouseLeft, ModMotion, 20, 15)
+	mouse(MouseRelease, ModNone, 20, 15)
+	assertPosition("top", 10, 0, 40, 15)
+	assertPosition("bottom", 10, 15, 40, 20)
+	assert.EqualValues(t, 0.75, inner.Ratio)
+
+	// dragging the outer divider, which is bounded by the minimum size of the
+	// side view
+	mouse(MouseLeft, ModNone, 10, 3)
+	mouse(MouseLeft, ModMotion, 2, 3)
+	assertPosition("side", 0, 0, 5, 20)
+	mouse(MouseLeft, ModMotion, 20, 3)
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventMouseMove, MouseX: 20, MouseY: 3}))
+	assertPosition("side", 0, 0, 20, 20)
+	assertPosition("top", 20, 0, 40, 15)
+
+	// the drag has ended
+	mouse(MouseLeft, ModMotion, 30, 3)
+	assertPosition("side", 0, 0, 20, 20)
+
+	// moving the divider from a keybinding
+	outer.MoveDivider(-4)
+	assert.NoError(t, layout.Layout(g))
+	assertPosition("side", 0, 0, 16, 20)
+	assertPosition("top", 16, 0, 40, 15)
+
+	// dragging with a modifier held
+	mouse(MouseLeft, ModShift, 16, 3)
+	mouse(MouseLeft, ModMotion|ModShift, 12, 3)
+	mouse(MouseLeft, ModMotion|ModShift, 14, 3)
+	assertPosition("side", 0, 0, 14, 20)
+	mouse(MouseRelease, ModNone, 14, 3)
+	mouse(MouseLeft, ModMotion, 30, 3)
+	assertPosition("side", 0, 0, 14, 20)
+}
+
+func TestSplitDividerTitle(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 41, Height: 21})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	split := SplitColumn(Flex(1), 0.5, ViewBox("top", Flex(1)), ViewBox("bottom", Flex(1)))
+	layout := NewLayout(split)
+	g.SetManager(layout)
+	assert.NoError(t, layout.Layout(g))
+	bottom, err := g.View("bottom")
+	assert.NoError(t, err)
+	bottom.Tabs = []string{"a", "b"}
+	clicked := -1
+	assert.NoError(t, g.SetTabClickBinding("bottom", func(tabIndex int) error {
+		clicked = tabIndex
+		return nil
+	}))
+
+	mouse := func(key Key, mod Modifier, x, y int) {
+		assert.NoError(t, g.onKey(&GocuiEvent{Type: eventMouse, Key: key, Mod: mod, MouseX: x, MouseY: y}))
+		assert.NoError(t, layout.Layout(g))
+	}
+
+	// a click on the tabs of the bottom view isn't a drag of the divider
+	mouse(MouseLeft, ModNone, 6, 10)
+	mouse(MouseLeft, ModMotion, 6, 15)
+	mouse(MouseRelease, ModNone, 6, 15)
+	assert.Equal(t, 1, clicked)
+	assert.EqualValues(t, 0.5, split.Ratio)
+
+	// the rest of the divider can be dragged
+	mouse(MouseLeft, ModNone, 20, 10)
+	mouse(MouseLeft, ModMotion, 20, 15)
+	mouse(MouseRelease, ModNone, 20, 15)
+	assert.EqualValues(t, 0.75, split.Ratio)
+}
@@ -0,0 +1,152 @@
+package gocui
+
+// Modal is a dialog shown centered on top of all the views. While it's open,
+// it has the focus and gets all the key and mouse events: keybindings of
+// other views and global keybindings don't fire.
+type Modal struct {
+	// Name is the name of the view of the modal.
+	Name string
+	// Width and Height are the size of the view, frame included. They are
+	// reduced to fit the screen.
+	Width  int
+	Height int
+	// Dim, if true, dims the views beneath the modal.
+	Dim bool
+	// OnCreate, if set, is called with the view of the modal when it is
+	// created, to set its content and keybindings.
+	OnCreate func(*View) error
+
+	view *View
+	// the view that had the focus when the modal was pushed
+	previousView *View
+}
+
+// PushModal opens a modal on top of the views and of the modals that are
+// already open, and gives it the focus.
+func (g *Gui) PushModal(m *Modal) (*View, error) {
+	x0, y0, x1, y1 := g.modalPosition(m)
+	v, err := g.SetView(m.Name, x0, y0, x1, y1, 0)
+	if err != nil && !IsUnknownView(err) {
+		return nil, err
+	}
+	v.Frame = true
+	v.Visible = true
+	v.Layer = LayerModal
+	v.ZIndex = len(g.modals)
+
+	m.view = v
+	m.previousView = g.currentView
+	g.modals = append(g.modals, m)
+
+	if _, err := g.SetViewZIndex(m.Name, v.ZIndex); err != nil {
+		return nil, err
+	}
+	if m.OnCreate != nil {
+		if err := m.OnCreate(v); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := g.SetCurrentView(m.Name); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// PopModal closes the topmost modal, deleting its view and keybindings, and
+// gives the focus back to the view that had it before. It does nothing if no
+// modal is open.
+func (g *Gui) PopModal() error {
+	m := g.Modal()
+	if m == nil {
+		return nil
+	}
+
+	g.modals = g.modals[:len(g.modals)-1]
+	g.DeleteViewKeybindings(m.Name)
+	if err := g.DeleteView(m.Name); err != nil {
+		return err
+	}
+
+	// the view may have been deleted in the meantime, in which case
+	// DeleteView gave the focus back to another one
+	if m.previousView != nil {
+		if v, err := g.View(m.previousView.Name()); err == nil && v == m.previousView {
+			return g.focus(v)
+		}
+	}
+
+	return nil
+}
+
+// Modal returns the topmost modal, or nil if none is open.
+func (g *Gui) Modal() *Modal {
+	if len(g.modals) == 0 {
+		return nil
+	}
+
+	return g.modals[len(g.modals)-1]
+}
+
+// removeModal drops the modal of a deleted view from the stack, in case the
+// view was deleted directly rather than with PopModal.
+func (g *Gui) removeModal(v *View) {
+	for i, m := range g.modals {
+		if m.view == v {
+			g.modals = append(g.modals[:i], g.modals[i+1:]...)
+			return
+		}
+	}
+}
+
+// modalPosition returns the coordinates of the view of a modal, centered on
+// the screen.
+func (g *Gui) modalPosition(m *Modal) (x0, y0, x1, y1 int) {
+	width := max(min(m.Width, g.maxX), 2)
+	height := max(min(m.Height, g.maxY), 2)
+	x0 = (g.maxX - width) / 2
+	y0 = (g.maxY - height) / 2
+
+	return x0, y0, x0 + width - 1, y0 + height - 1
+}
+
+// layoutModals keeps the modals centered when the screen is resized.
+func (g *Gui) layoutModals() error {
+	for _, m := range g.modals {
+		x0, y0, x1, y1 := g.modalPosition(m)
+		if _, err := g.SetView(m.Name, x0, y0, x1, y1, 0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dimmedBeneath returns the view beneath which the screen is dimmed, i.e. the
+// view of the topmost modal that asks for it, or nil.
+func (g *Gui) dimmedBeneath() *View {
+	for i := len(g.modals) - 1; i >= 0; i-- {
+		if g.modals[i].Dim {
+			return g.modals[i].view
+		}
+	}
+
+	return nil
+}
+
+// dimScreen dims everything drawn on the screen so far.
+func (g *Gui) dimScreen() {
+	for y := 0; y < g.maxY; y++ {
+		for x := 0; x < g.maxX; x++ {
+			mainc, combc, style, _ := Screen.GetContent(x, y)
+			Screen.SetContent(x, y, mainc, combc, style.Dim(true))
+		}
+	}
+}
+
+// modalBlocks reports whether an open modal keeps events from reaching the
+// given view.
+func (g *Gui) modalBlocks(v *View) bool {
+	m := g.Modal()
+	return m != nil && v != m.view
+}
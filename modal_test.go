@@ -0,0 +1,185 @@
+package gocui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModal(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 40, Height: 20})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	main, _ := g.SetView("main", 0, 0, 39, 19, 0)
+	_, _ = g.SetCurrentView("main")
+
+	pressed := []string{}
+	press := func(name string) func(*Gui, *View) error {
+		return func(*Gui, *View) error {
+			pressed = append(pressed, name)
+			return nil
+		}
+	}
+	assert.NoError(t, g.SetKeybinding("", 'q', ModNone, press("global")))
+	assert.NoError(t, g.SetKeybinding("main", 'x', ModNone, press("main")))
+	assert.NoError(t, g.SetViewClickBinding(&ViewMouseBinding{ViewName: "main", Key: MouseLeft, Handler: func(ViewMouseBindingOpts) error {
+		pressed = append(pressed, "main click")
+		return nil
+	}}))
+
+	v, err := g.PushModal(&Modal{
+		Name:   "confirm",
+		Width:  20,
+		Height: 6,
+		Dim:    true,
+		OnCreate: func(v *View) error {
+			v.Title = "Confirm"
+			return g.SetKeybinding("confirm", 'y', ModNone, press("confirm"))
+		},
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, "Confirm", v.Title)
+	assert.EqualValues(t, v, g.CurrentView())
+
+	x0, y0, x1, y1, err := g.ViewPosition("confirm")
+	assert.NoError(t, err)
+	assert.EqualValues(t, []int{10, 7, 29, 12}, []int{x0, y0, x1, y1})
+
+	key := func(ch rune) {
+		assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Ch: ch}))
+	}
+	click := func(x, y int) {
+		assert.NoError(t, g.onKey(&GocuiEvent{Type: eventMouse, Key: MouseLeft, MouseX: x, MouseY: y}))
+	}
+
+	// the focus can't be moved away from the modal, which gets all the events
+	_, _ = g.SetCurrentView("main")
+	assert.EqualValues(t, v, g.CurrentView())
+	key('q')
+	key('x')
+	key('y')
+	click(1, 1)
+	assert.EqualValues(t, []string{"confirm"}, pressed)
+
+	// the views beneath are dimmed
+	assert.NoError(t, g.flush())
+	_, _, style, _ := Screen.GetContent(1, 1)
+	_, _, attrs := style.Decompose()
+	assert.True(t, attrs&tcell.AttrDim != 0)
+	_, _, style, _ = Screen.GetContent(15, 9)
+	_, _, attrs = style.Decompose()
+	assert.False(t, attrs&tcell.AttrDim != 0)
+
+	// popping the modal restores the focus and the keybindings
+	assert.NoError(t, g.PopModal())
+	assert.Nil(t, g.Modal())
+	assert.EqualValues(t, main, g.CurrentView())
+	_, err = g.View("confirm")
+	assert.Error(t, err)
+	pressed = nil
+	key('q')
+	key('y')
+	click(1, 1)
+	assert.EqualValues(t, []string{"global", "main click"}, pressed)
+}
+
+func TestModalFocus(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 40, Height: 20})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	focused := []string{}
+	onFocus := func(v *View) error {
+		focused = append(focused, "focus "+v.Name())
+		return nil
+	}
+	onBlur := func(v *View) error {
+		focused = append(focused, "blur "+v.Name())
+		return nil
+	}
+	main, _ := g.SetView("main", 0, 0, 39, 19, 0)
+	main.OnFocus, main.OnBlur = onFocus, onBlur
+	_, _ = g.SetCurrentView("main")
+
+	_, err = g.PushModal(&Modal{Name: "confirm", Width: 20, Height: 6, OnCreate: func(v *View) error {
+		v.OnFocus, v.OnBlur = onFocus, onBlur
+		return nil
+	}})
+	assert.NoError(t, err)
+
+	// keys and attempts to move the focus away don't fire the callbacks
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Ch: 'a'}))
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Ch: 'b'}))
+	_, err = g.SetCurrentView("main")
+	assert.NoError(t, err)
+	_, err = g.FocusNext()
+	assert.NoError(t, err)
+
+	assert.NoError(t, g.PopModal())
+	assert.EqualValues(t, []string{
+		"focus main",
+		"blur main",
+		"focus confirm",
+		"blur confirm",
+		"focus main",
+	}, focused)
+}
+
+func TestModalViewDeleted(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 40, Height: 20})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	_, _ = g.SetView("main", 0, 0, 39, 19, 0)
+	_, _ = g.SetCurrentView("main")
+	pressed := 0
+	assert.NoError(t, g.SetKeybinding("", 'q', ModNone, func(*Gui, *View) error {
+		pressed++
+		return nil
+	}))
+
+	_, err = g.PushModal(&Modal{Name: "confirm", Width: 20, Height: 6})
+	assert.NoError(t, err)
+
+	// deleting the view directly closes the modal
+	assert.NoError(t, g.DeleteView("confirm"))
+	assert.Nil(t, g.Modal())
+	assert.NoError(t, g.flush())
+	_, err = g.View("confirm")
+	assert.True(t, IsUnknownView(err))
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Ch: 'q'}))
+	assert.Equal(t, 1, pressed)
+}
+
+func TestModalSetManager(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 40, Height: 20})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	_, err = g.PushModal(&Modal{Name: "confirm", Width: 20, Height: 6})
+	assert.NoError(t, err)
+
+	// a new manager starts without modals
+	g.SetManagerFunc(func(g *Gui) error {
+		_, err := g.SetView("main", 0, 0, 39, 19, 0)
+		if err != nil && !IsUnknownView(err) {
+			return err
+		}
+		return nil
+	})
+	assert.Nil(t, g.Modal())
+	assert.NoError(t, g.flush())
+	_, err = g.View("confirm")
+	assert.True(t, IsUnknownView(err))
+	_, _ = g.SetCurrentView("main")
+
+	pressed := 0
+	assert.NoError(t, g.SetKeybinding("", 'q', ModNone, func(*Gui, *View) error {
+		pressed++
+		return nil
+	}))
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Ch: 'q'}))
+	assert.Equal(t, 1, pressed)
+}
@@ -0,0 +1,263 @@
+package gocui
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// MouseEventType tells what happened with the mouse in a mouse event.
+type MouseEventType int
+
+// Mouse event types.
+const (
+	// MouseEventPress is a button press, or a wheel movement. It's the
+	// default event of a ViewMouseBinding: such bindings also match the
+	// motions of a drag when their Modifier is ModMotion, as they always have.
+	MouseEventPress MouseEventType = iota
+	// MouseEventRelease is the release of a button that wasn't dragged.
+	MouseEventRelease
+	// MouseEventMove is a motion with no button down.
+	MouseEventMove
+	// MouseEventDragStart is the first motion with a button held down.
+	MouseEventDragStart
+	// MouseEventDragMove is a following motion with a button held down.
+	MouseEventDragMove
+	// MouseEventDragEnd is the release of a button that was dragged.
+	MouseEventDragEnd
+	// MouseEventEnter is the pointer moving over a view.
+	MouseEventEnter
+	// MouseEventLeave is the pointer moving away from a view.
+	MouseEventLeave
+)
+
+// maxClickCount is the number of clicks after which counting starts over,
+// so that clicking on and on alternates between single, double and triple
+// clicks.
+const maxClickCount = 3
+
+// mouseState turns the mouse events of tcell, which tell which buttons are
+// down, into presses, releases and drags, and counts the clicks.
+type mouseState struct {
+	// the button held down, if any, and the modifiers when it was pressed
+	button    tcell.ButtonMask
+	mod       Modifier
+	dragState int
+	// where the button was pressed
+	pressX, pressY int
+
+	clickCount      int
+	lastClick       time.Time
+	lastClickX      int
+	lastClickY      int
+	lastClickButton tcell.ButtonMask
+}
+
+// mouseButtonKeys maps the buttons of tcell to mouse keys, the first one
+// winning when several buttons are down.
+var mouseButtonKeys = []struct {
+	button tcell.ButtonMask
+	key    Key
+}{
+	{tcell.ButtonPrimary, MouseLeft},
+	{tcell.ButtonSecondary, MouseRight},
+	{tcell.ButtonMiddle, MouseMiddle},
+}
+
+// mouseWheelKeys maps the wheel movements of tcell to mouse keys.
+var mouseWheelKeys = []struct {
+	button tcell.ButtonMask
+	key    Key
+}{
+	{tcell.WheelUp, MouseWheelUp},
+	{tcell.WheelDown, MouseWheelDown},
+	{tcell.WheelLeft, MouseWheelLeft},
+	{tcell.WheelRight, MouseWheelRight},
+}
+
+func mouseButtonKey(button tcell.ButtonMask) Key {
+	for _, b := range mouseButtonKeys {
+		if b.button == button {
+			return b.key
+		}
+	}
+	return MouseRelease
+}
+
+// event returns the event of a mouse event of tcell. Presses are eventMouse
+// events, with ClickCount set to 2 or 3 for repeated clicks of the same
+// button at the same position within doubleClickInterval. Motions with a
+// button down are eventMouse events too, with ModMotion. Releases and
+// motions with no button down are eventMouseMove events. The origin of
+// events is where the button was pressed, or the position of the pointer
+// for events without a button.
+func (s *mouseState) event(x, y int, buttons tcell.ButtonMask, mods tcell.ModMask, now time.Time, doubleClickInterval time.Duration) GocuiEvent {
+	mod := Modifier(mods) & (ModShift | ModCtrl | ModAlt | ModMeta)
+
+	for _, w := range mouseWheelKeys {
+		if buttons&w.button != 0 {
+			return GocuiEvent{Type: eventMouse, MouseX: x, MouseY: y, Key: w.key, Mod: mod, OriginX: x, OriginY: y}
+		}
+	}
+
+	if s.button == tcell.ButtonNone {
+		pressed := tcell.ButtonNone
+		for _, b := range mouseButtonKeys {
+			if buttons&b.button != 0 {
+				pressed = b.button
+				break
+			}
+		}
+		if pressed == tcell.ButtonNone {
+			return GocuiEvent{Type: eventMouseMove, MouseX: x, MouseY: y, MouseEvent: MouseEventMove, OriginX: x, OriginY: y}
+		}
+
+		if s.clickCount < maxClickCount && pressed == s.lastClickButton && x == s.lastClickX && y == s.lastClickY && now.Sub(s.lastClick) <= doubleClickInterval {
+			s.clickCount++
+		} else {
+			s.clickCount = 1
+		}
+		s.lastClick, s.lastClickX, s.lastClickY, s.lastClickButton = now, x, y, pressed
+
+		s.button, s.mod, s.dragState = pressed, mod, MAYBE_DRAGGING
+		s.pressX, s.pressY = x, y
+		return GocuiEvent{
+			Type:       eventMouse,
+			MouseX:     x,
+			MouseY:     y,
+			Key:        mouseButtonKey(pressed),
+			Mod:        mod,
+			MouseEvent: MouseEventPress,
+			ClickCount: s.clickCount,
+			OriginX:    x,
+			OriginY:    y,
+		}
+	}
+
+	ev := GocuiEvent{
+		MouseX:  x,
+		MouseY:  y,
+		Key:     mouseButtonKey(s.button),
+		Mod:     s.mod,
+		OriginX: s.pressX,
+		OriginY: s.pressY,
+	}
+
+	if buttons&s.button == 0 {
+		ev.Type = eventMouseMove
+		ev.MouseEvent = MouseEventRelease
+		if s.dragState == DRAGGING {
+			ev.MouseEvent = MouseEventDragEnd
+		}
+		s.button, s.mod, s.dragState = tcell.ButtonNone, ModNone, NOT_DRAGGING
+		return ev
+	}
+
+	// if we haven't released the button and we've moved the cursor then
+	// we're dragging
+	ev.Type = eventMouse
+	ev.Mod |= ModMotion
+	switch {
+	case s.dragState == DRAGGING:
+		ev.MouseEvent = MouseEventDragMove
+	case x != s.pressX || y != s.pressY:
+		s.dragState = DRAGGING
+		ev.MouseEvent = MouseEventDragStart
+	default:
+		return GocuiEvent{Type: eventNone}
+	}
+	return ev
+}
+
+// matches returns whether the mouse binding matches the event, which is of
+// the binding's Event type.
+func (b *ViewMouseBinding) matches(ev *GocuiEvent) bool {
+	switch b.Event {
+	case MouseEventPress:
+		return ev.Type == eventMouse && ev.Key == b.Key && ev.Mod == b.Modifier &&
+			(b.ClickCount == 0 || b.ClickCount == ev.ClickCount)
+	case MouseEventMove, MouseEventEnter, MouseEventLeave:
+		return true
+	default:
+		return ev.Key == b.Key && ev.Mod&^ModMotion == b.Modifier
+	}
+}
+
+// mousePosition returns the position of the pointer relative to the content
+// of the view, independent of its scroll position.
+func (v *View) mousePosition(x, y int) (int, int) {
+	cy := y - v.y0 - 1
+	cx := v.logicalX(x-v.x0-1, cy)
+	return cx + v.ox, cy + v.oy
+}
+
+// mouseBindingOpts returns the options passed to the mouse bindings of the
+// view for the event.
+func (v *View) mouseBindingOpts(ev *GocuiEvent) ViewMouseBindingOpts {
+	x, y := v.mousePosition(ev.MouseX, ev.MouseY)
+	originX, originY := v.mousePosition(ev.OriginX, ev.OriginY)
+	return ViewMouseBindingOpts{
+		X:          x,
+		Y:          y,
+		ClickCount: ev.ClickCount,
+		OriginX:    originX,
+		OriginY:    originY,
+	}
+}
+
+// onMouseButton passes the drag and release events to the drag-and-drop in
+// progress, if any, or else to the mouse selection and the mouse bindings of
+// the view the button was pressed in. It returns true if the drag-and-drop or
+// one of the bindings handled the event.
+func (g *Gui) onMouseButton(ev *GocuiEvent) (bool, error) {
+	v := g.mouseDownView
+	switch ev.MouseEvent {
+	case MouseEventPress:
+		if IsMouseScrollKey(ev.Key) {
+			return false, nil
+		}
+		g.mouseDownView = nil
+		if v, err := g.VisibleViewByPosition(ev.MouseX, ev.MouseY); err == nil && !g.modalBlocks(v) {
+			g.mouseDownView = v
+		}
+		return false, g.onMouseSelection(g.mouseDownView, ev)
+	case MouseEventRelease, MouseEventDragEnd:
+		g.mouseDownView = nil
+	case MouseEventDragStart, MouseEventDragMove:
+	default:
+		return false, nil
+	}
+	if handled, err := g.onDrag(v, ev); handled || err != nil {
+		return true, err
+	}
+	if v == nil {
+		return false, nil
+	}
+	if err := g.onMouseSelection(v, ev); err != nil {
+		return false, err
+	}
+	return g.execMouseKeybindings(v, ev, ev.MouseEvent, v.mouseBindingOpts(ev))
+}
+
+// setMouseOverView records the view under the pointer, calling the
+// MouseEventLeave bindings of the previous one and the MouseEventEnter
+// bindings of the new one if it changed.
+func (g *Gui) setMouseOverView(v *View, ev *GocuiEvent) error {
+	previous := g.mouseOverView
+	if v == previous {
+		return nil
+	}
+	g.mouseOverView = v
+
+	if previous != nil {
+		if _, err := g.execMouseKeybindings(previous, ev, MouseEventLeave, previous.mouseBindingOpts(ev)); err != nil {
+			return err
+		}
+	}
+	if v != nil {
+		if _, err := g.execMouseKeybindings(v, ev, MouseEventEnter, v.mouseBindingOpts(ev)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package gocui
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMouseState(t *testing.T) {
+	type input struct {
+		x, y    int
+		buttons tcell.ButtonMask
+		mods    tcell.ModMask
+		after   time.Duration
+	}
+	tests := []struct {
+		name     string
+		inputs   []input
+		expected []GocuiEvent
+	}{
+		{
+			name: "click",
+			inputs: []input{
+				{x: 1, y: 2, buttons: tcell.ButtonPrimary},
+				{x: 1, y: 2},
+			},
+			expected: []GocuiEvent{
+				{Type: eventMouse, MouseX: 1, MouseY: 2, Key: MouseLeft, MouseEvent: MouseEventPress, ClickCount: 1, OriginX: 1, OriginY: 2},
+				{Type: eventMouseMove, MouseX: 1, MouseY: 2, Key: MouseLeft, MouseEvent: MouseEventRelease, OriginX: 1, OriginY: 2},
+			},
+		},
+		{
+			name: "double and triple click",
+			inputs: []input{
+				{x: 1, y: 2, buttons: tcell.ButtonPrimary},
+				{x: 1, y: 2},
+				{x: 1, y: 2, buttons: tcell.ButtonPrimary, after: 100 * time.Millisecond},
+				{x: 1, y: 2},
+				{x: 1, y: 2, buttons: tcell.ButtonPrimary, after: 100 * time.Millisecond},
+				{x: 1, y: 2},
+				{x: 1, y: 2, buttons: tcell.ButtonPrimary, after: 100 * time.Millisecond},
+			},
+			expected: []GocuiEvent{
+				{Type: eventMouse, MouseX: 1, MouseY: 2, Key: MouseLeft, MouseEvent: MouseEventPress, ClickCount: 1, OriginX: 1, OriginY: 2},
+				{Type: eventMouseMove, MouseX: 1, MouseY: 2, Key: MouseLeft, MouseEvent: MouseEventRelease, OriginX: 1, OriginY: 2},
+				{Type: eventMouse, MouseX: 1, MouseY: 2, Key: MouseLeft, MouseEvent: MouseEventPress, ClickCount: 2, OriginX: 1, OriginY: 2},
+				{Type: eventMouseMove, MouseX: 1, MouseY: 2, Key: MouseLeft, MouseEvent: MouseEventRelease, OriginX: 1, OriginY: 2},
+				{Type: eventMouse, MouseX: 1, MouseY: 2, Key: MouseLeft, MouseEvent: MouseEventPress, ClickCount: 3, OriginX: 1, OriginY: 2},
+				{Type: eventMouseMove, MouseX: 1, MouseY: 2, Key: MouseLeft, MouseEvent: MouseEventRelease, OriginX: 1, OriginY: 2},
+				{Type: eventMouse, MouseX: 1, MouseY: 2, Key: MouseLeft, MouseEvent: MouseEventPress, ClickCount: 1, OriginX: 1, OriginY: 2},
+			},
+		},
+		{
+			name: "slow clicks",
+			inputs: []input{
+				{x: 1, y: 2, buttons: tcell.ButtonPrimary},
+				{x: 1, y: 2},
+				{x: 1, y: 2, buttons: tcell.ButtonPrimary, after: time.Second},
+			},
+			expected: []GocuiEvent{
+				{Type: eventMouse, MouseX: 1, MouseY: 2, Key: MouseLeft, MouseEvent: MouseEventPress, ClickCount: 1, OriginX: 1, OriginY: 2},
+				{Type: eventMouseMove, MouseX: 1, MouseY: 2, Key: MouseLeft, MouseEvent: MouseEventRelease, OriginX: 1, OriginY: 2},
+				{Type: eventMouse, MouseX: 1, MouseY: 2, Key: MouseLeft, MouseEvent: MouseEventPress, ClickCount: 1, OriginX: 1, OriginY: 2},
+			},
+		},
+		{
+			name: "right drag with a modifier",
+			inputs: []input{
+				{x: 1, y: 2, buttons: tcell.ButtonSecondary, mods: tcell.ModCtrl},
+				{x: 1, y: 2, buttons: tcell.ButtonSecondary, mods: tcell.ModCtrl},
+				{x: 2, y: 2, buttons: tcell.ButtonSecondary, mods: tcell.ModCtrl},
+				{x: 3, y: 3, buttons: tcell.ButtonSecondary},
+				{x: 3, y: 3},
+				{x: 4, y: 3},
+			},
+			expected: []GocuiEvent{
+				{Type: eventMouse, MouseX: 1, MouseY: 2, Key: MouseRight, Mod: ModCtrl, MouseEvent: MouseEventPress, ClickCount: 1, OriginX: 1, OriginY: 2},
+				{Type: eventNone},
+				{Type: eventMouse, MouseX: 2, MouseY: 2, Key: MouseRight, Mod: ModCtrl | ModMotion, MouseEvent: MouseEventDragStart, OriginX: 1, OriginY: 2},
+				{Type: eventMouse, MouseX: 3, MouseY: 3, Key: MouseRight, Mod: ModCtrl | ModMotion, MouseEvent: MouseEventDragMove, OriginX: 1, OriginY: 2},
+				{Type: eventMouseMove, MouseX: 3, MouseY: 3, Key: MouseRight, Mod: ModCtrl, MouseEvent: MouseEventDragEnd, OriginX: 1, OriginY: 2},
+				{Type: eventMouseMove, MouseX: 4, MouseY: 3, MouseEvent: MouseEventMove, OriginX: 4, OriginY: 3},
+			},
+		},
+		{
+			name: "wheel",
+			inputs: []input{
+				{x: 1, y: 2, buttons: tcell.WheelDown, mods: tcell.ModShift},
+			},
+			expected: []GocuiEvent{
+				{Type: eventMouse, MouseX: 1, MouseY: 2, Key: MouseWheelDown, Mod: ModShift, OriginX: 1, OriginY: 2},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &mouseState{}
+			now := time.Now()
+			events := []GocuiEvent{}
+			for _, in := range test.inputs {
+				now = now.Add(in.after)
+				events = append(events, s.event(in.x, in.y, in.buttons, in.mods, now, 500*time.Millisecond))
+			}
+			assert.EqualValues(t, test.expected, events)
+		})
+	}
+}
+
+func TestMouseBindings(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 80, Height: 24})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	for _, name := range []string{"left", "right"} {
+		x0 := 0
+		if name == "right" {
+			x0 = 40
+		}
+		if _, err := g.SetView(name, x0, 0, x0+39, 10, 0); !IsUnknownView(err) {
+			assert.NoError(t, err)
+		}
+	}
+
+	handled := []string{}
+	bind := func(viewName string, key Key, event MouseEventType, clickCount int) {
+		assert.NoError(t, g.SetViewClickBinding(&ViewMouseBinding{
+			ViewName:   viewName,
+			Key:        key,
+			Event:      event,
+			ClickCount: clickCount,
+			Handler: func(opts ViewMouseBindingOpts) error {
+				handled = append(handled, fmt.Sprintf("%s %d/%d %d,%d %d,%d", viewName, event, clickCount, opts.X, opts.Y, opts.OriginX, opts.OriginY))
+				return nil
+			},
+		}))
+	}
+	bind("left", MouseLeft, MouseEventPress, 0)
+	bind("left", MouseLeft, MouseEventPress, 2)
+	bind("left", MouseLeft, MouseEventDragStart, 0)
+	bind("left", MouseLeft, MouseEventDragEnd, 0)
+	bind("right", 0, MouseEventEnter, 0)
+	bind("right", 0, MouseEventLeave, 0)
+
+	mouse := func(x, y int, buttons tcell.ButtonMask) {
+		ev := g.mouseState.event(x, y, buttons, tcell.ModNone, time.Now(), g.DoubleClickInterval)
+		if ev.Type != eventNone {
+			assert.NoError(t, g.onKey(&ev))
+		}
+	}
+
+	// a click, then a double click
+	mouse(5, 3, tcell.ButtonPrimary)
+	mouse(5, 3, tcell.ButtonNone)
+	mouse(5, 3, tcell.ButtonPrimary)
+	mouse(5, 3, tcell.ButtonNone)
+	assert.EqualValues(t, []string{
+		fmt.Sprintf("left %d/0 4,2 4,2", MouseEventPress),
+		fmt.Sprintf("left %d/2 4,2 4,2", MouseEventPress),
+	}, handled)
+
+	// a drag into the other view, reported to the view it started in
+	handled = []string{}
+	mouse(10, 3, tcell.ButtonPrimary)
+	mouse(45, 4, tcell.ButtonPrimary)
+	mouse(46, 4, tcell.ButtonPrimary)
+	mouse(46, 4, tcell.ButtonNone)
+	mouse(10, 4, tcell.ButtonNone)
+	assert.EqualValues(t, []string{
+		fmt.Sprintf("left %d/0 9,2 9,2", MouseEventPress),
+		fmt.Sprintf("right %d/0 4,3 -31,2", MouseEventEnter),
+		fmt.Sprintf("left %d/0 44,3 9,2", MouseEventDragStart),
+		fmt.Sprintf("left %d/0 45,3 9,2", MouseEventDragEnd),
+		fmt.Sprintf("right %d/0 -31,3 -31,3", MouseEventLeave),
+	}, handled)
+}
+
+func TestMouseBindingsSetManager(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 80, Height: 24})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	if _, err := g.SetView("old", 0, 0, 39, 10, 0); !IsUnknownView(err) {
+		assert.NoError(t, err)
+	}
+	handled := []string{}
+	assert.NoError(t, g.SetViewClickBinding(&ViewMouseBinding{
+		ViewName: "old",
+		Event:    MouseEventLeave,
+		Handler: func(ViewMouseBindingOpts) error {
+			handled = append(handled, "leave old")
+			return nil
+		},
+	}))
+
+	mouse := func(x, y int, buttons tcell.ButtonMask) {
+		ev := g.mouseState.event(x, y, buttons, tcell.ModNone, time.Now(), g.DoubleClickInterval)
+		if ev.Type != eventNone {
+			assert.NoError(t, g.onKey(&ev))
+		}
+	}
+	mouse(5, 3, tcell.ButtonPrimary)
+
+	// the views of the previous manager are forgotten
+	g.SetManagerFunc(func(*Gui) error { return nil })
+	assert.Nil(t, g.mouseDownView)
+	assert.Nil(t, g.mouseOverView)
+	mouse(50, 3, tcell.ButtonPrimary)
+	mouse(50, 3, tcell.ButtonNone)
+	assert.Empty(t, handled)
+}
@@ -0,0 +1,249 @@
+package gocui
+
+import (
+	"time"
+)
+
+// KeyPress is one of the keys of a key sequence: a rune or a Key, pressed
+// with the given modifier.
+type KeyPress struct {
+	Key interface{}
+	Mod Modifier
+}
+
+// sequenceBinding is a keybinding that fires after a sequence of key presses,
+// like `g g` or `Ctrl+X Ctrl+S`.
+type sequenceBinding struct {
+	viewName string
+	presses  []keyPress
+	handler  func(*Gui, *View) error
+
+	// shown in the help view
+	category    string
+	description string
+}
+
+// keyPress is a KeyPress with its key resolved.
+type keyPress struct {
+	key Key
+	ch  rune
+	mod Modifier
+}
+
+func (p keyPress) matches(ev *GocuiEvent) bool {
+	key, mod := extendedKey(p.key, p.mod)
+	return p.ch == ev.Ch && (p.key == ev.Key && p.mod == ev.Mod || key == ev.Key && mod == ev.Mod)
+}
+
+// pendingSequence holds the keys typed so far of a sequence.
+type pendingSequence struct {
+	events []GocuiEvent
+	// incremented for each new sequence, so that the timer of an abandoned
+	// sequence doesn't affect the next one
+	id    int
+	timer *time.Timer
+}
+
+// SetSequenceKeybinding creates a keybinding that fires after the given keys
+// are pressed one after the other, each within KeySequenceTimeout of the
+// previous one. If viewname is empty, it applies to all views. Sequences
+// starting with a rune don't apply to editable views.
+//
+// The keys pressed are held back while they may be the start of a sequence. If
+// the sequence is abandoned, because another key is pressed or it times out,
+// they are handled as single keys.
+func (g *Gui) SetSequenceKeybinding(viewname string, sequence []KeyPress, handler func(*Gui, *View) error) error {
+	if len(sequence) == 0 {
+		return ErrInvalidKeySequence
+	}
+
+	presses := make([]keyPress, len(sequence))
+	for i, press := range sequence {
+		k, ch, err := getKey(press.Key)
+		if err != nil {
+			return err
+		}
+		if g.isBlacklisted(k) {
+			return ErrBlacklisted
+		}
+		presses[i] = keyPress{key: k, ch: ch, mod: press.Mod}
+	}
+
+	return g.addSequenceBinding(&sequenceBinding{
+		viewName: viewname,
+		presses:  presses,
+		handler:  handler,
+	})
+}
+
+// DeleteSequenceKeybindings deletes all sequence keybindings of a view, and
+// drops the keys typed so far of a sequence.
+func (g *Gui) DeleteSequenceKeybindings(viewname string) {
+	var s []*sequenceBinding
+	for _, sb := range g.sequenceBindings {
+		if sb.viewName != viewname {
+			s = append(s, sb)
+		}
+	}
+	g.sequenceBindings = s
+	g.dropPendingSequence()
+}
+
+// PendingKeySequence returns the keys typed so far of a sequence keybinding.
+func (g *Gui) PendingKeySequence() []KeyPress {
+	presses := make([]KeyPress, len(g.pendingSequence.events))
+	for i, ev := range g.pendingSequence.events {
+		presses[i] = eventKeyPress(&ev)
+	}
+	return presses
+}
+
+func eventKeyPress(ev *GocuiEvent) KeyPress {
+	if ev.Ch != 0 {
+		return KeyPress{Key: ev.Ch, Mod: ev.Mod}
+	}
+	return KeyPress{Key: ev.Key, Mod: ev.Mod}
+}
+
+// applies reports whether the sequence keybinding applies to the view.
+func (sb *sequenceBinding) applies(g *Gui, v *View) bool {
+	if sb.handler == nil {
+		return false
+	}
+	if sb.viewName == "" && g.Modal() != nil {
+		return false
+	}
+	if sb.viewName != "" && (v == nil || v.name != sb.viewName) {
+		return false
+	}
+
+	// if the user is typing in a field, ignore sequences starting with a char
+	return v == nil || !v.Editable || sb.presses[0].ch == 0
+}
+
+// startsWith reports whether the sequence starts with the given key events.
+func (sb *sequenceBinding) startsWith(events []GocuiEvent) bool {
+	if len(events) > len(sb.presses) {
+		return false
+	}
+	for i := range events {
+		if !sb.presses[i].matches(&events[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// onSequenceKey handles a key event that may be part of a key sequence. It
+// returns true if the event was consumed.
+func (g *Gui) onSequenceKey(ev *GocuiEvent) (bool, error) {
+	if len(g.sequenceBindings) == 0 {
+		return false, nil
+	}
+
+	v := g.currentView
+	typed := append(append([]GocuiEvent{}, g.pendingSequence.events...), *ev)
+
+	var complete *sequenceBinding
+	isPrefix := false
+	for _, sb := range g.sequenceBindings {
+		if !sb.applies(g, v) || !sb.startsWith(typed) {
+			continue
+		}
+		if len(sb.presses) == len(typed) {
+			if complete == nil {
+				complete = sb
+			}
+		} else {
+			isPrefix = true
+		}
+	}
+
+	switch {
+	case complete != nil && !isPrefix:
+		if err := g.clearPendingSequence(); err != nil {
+			return true, err
+		}
+		return true, complete.handler(g, v)
+	case complete != nil || isPrefix:
+		return true, g.setPendingSequence(typed)
+	case len(g.pendingSequence.events) == 0:
+		return false, nil
+	default:
+		// the sequence was abandoned; we handle the keys typed so far as
+		// single keys, and the new one as if no sequence was pending
+		if err := g.abandonSequence(); err != nil {
+			return true, err
+		}
+		if handled, err := g.onSequenceKey(ev); handled || err != nil {
+			return true, err
+		}
+		return true, g.execKeybindings(g.currentView, ev)
+	}
+}
+
+func (g *Gui) setPendingSequence(events []GocuiEvent) error {
+	if g.pendingSequence.timer != nil {
+		g.pendingSequence.timer.Stop()
+	}
+	g.pendingSequence.events = events
+	g.pendingSequence.id++
+
+	if g.KeySequenceTimeout > 0 {
+		id := g.pendingSequence.id
+		g.pendingSequence.timer = time.AfterFunc(g.KeySequenceTimeout, func() {
+			g.Update(func(g *Gui) error {
+				if g.pendingSequence.id != id || len(g.pendingSequence.events) == 0 {
+					return nil
+				}
+				return g.abandonSequence()
+			})
+		})
+	}
+
+	if g.OnPendingKeySequence != nil {
+		return g.OnPendingKeySequence(g.PendingKeySequence())
+	}
+	return nil
+}
+
+func (g *Gui) clearPendingSequence() error {
+	if len(g.pendingSequence.events) == 0 {
+		return nil
+	}
+
+	return g.setPendingSequence(nil)
+}
+
+// dropPendingSequence forgets the pending sequence without handling its keys.
+func (g *Gui) dropPendingSequence() {
+	if g.pendingSequence.timer != nil {
+		g.pendingSequence.timer.Stop()
+	}
+	g.pendingSequence = pendingSequence{id: g.pendingSequence.id + 1}
+}
+
+// abandonSequence gives up on the pending sequence. If the keys typed so far
+// form a whole sequence, which was held back because a longer one starts the
+// same way, it fires; otherwise they are handled as single keys.
+func (g *Gui) abandonSequence() error {
+	events := g.pendingSequence.events
+	if err := g.clearPendingSequence(); err != nil {
+		return err
+	}
+
+	v := g.currentView
+	for _, sb := range g.sequenceBindings {
+		if sb.applies(g, v) && len(sb.presses) == len(events) && sb.startsWith(events) {
+			return sb.handler(g, v)
+		}
+	}
+
+	for i := range events {
+		if err := g.execKeybindings(g.currentView, &events[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package gocui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSequenceKeybindings(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 40, Height: 20})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	g.KeySequenceTimeout = 0
+	_, _ = g.SetView("main", 0, 0, 10, 10, 0)
+	_, _ = g.SetCurrentView("main")
+
+	pressed := []string{}
+	press := func(name string) func(*Gui, *View) error {
+		return func(*Gui, *View) error {
+			pressed = append(pressed, name)
+			return nil
+		}
+	}
+	pending := []string{}
+	g.OnPendingKeySequence = func(keys []KeyPress) error {
+		s := ""
+		for _, key := range keys {
+			if ch, ok := key.Key.(rune); ok {
+				s += string(ch)
+			} else {
+				s += "<key>"
+			}
+		}
+		pending = append(pending, s)
+		return nil
+	}
+
+	assert.NoError(t, g.SetSequenceKeybinding("main", []KeyPress{{Key: 'g'}, {Key: 'g'}}, press("top")))
+	assert.NoError(t, g.SetSequenceKeybinding("", []KeyPress{{Key: KeyCtrlX}, {Key: KeyCtrlS}}, press("save")))
+	assert.NoError(t, g.SetSequenceKeybinding("", []KeyPress{{Key: ' '}, {Key: 'f'}}, press("leader f")))
+	assert.NoError(t, g.SetSequenceKeybinding("", []KeyPress{{Key: ' '}, {Key: 'f'}, {Key: 'f'}}, press("leader f f")))
+	assert.NoError(t, g.SetKeybinding("main", 'g', ModNone, press("g")))
+	assert.NoError(t, g.SetKeybinding("main", 'j', ModNone, press("j")))
+	assert.Equal(t, ErrInvalidKeySequence, g.SetSequenceKeybinding("", nil, press("nothing")))
+
+	keys := func(events ...GocuiEvent) {
+		for _, ev := range events {
+			ev := ev
+			ev.Type = eventKey
+			assert.NoError(t, g.onKey(&ev))
+		}
+	}
+	ch := func(ch rune) GocuiEvent { return GocuiEvent{Ch: ch} }
+	key := func(key Key) GocuiEvent { return GocuiEvent{Key: key} }
+
+	tests := []struct {
+		name            string
+		events          []GocuiEvent
+		expectedPressed []string
+		expectedPending []string
+	}{
+		{
+			name:            "sequence",
+			events:          []GocuiEvent{ch('g'), ch('g')},
+			expectedPressed: []string{"top"},
+			expectedPending: []string{"g", ""},
+		},
+		{
+			name:            "sequence with keys",
+			events:          []GocuiEvent{key(KeyCtrlX), key(KeyCtrlS)},
+			expectedPressed: []string{"save"},
+			expectedPending: []string{"<key>", ""},
+		},
+		{
+			name:            "single keys are not held back",
+			events:          []GocuiEvent{ch('j')},
+			expectedPressed: []string{"j"},
+			expectedPending: []string{},
+		},
+		{
+			name:            "abandoned sequence falls back to single keys",
+			events:          []GocuiEvent{ch('g'), ch('j')},
+			expectedPressed: []string{"g", "j"},
+			expectedPending: []string{"g", ""},
+		},
+		{
+			name:            "abandoned sequence followed by another one",
+			events:          []GocuiEvent{ch('g'), key(KeyCtrlX), key(KeyCtrlS)},
+			expectedPressed: []string{"g", "save"},
+			expectedPending: []string{"g", "", "<key>", ""},
+		},
+		{
+			name:            "longer sequence",
+			events:          []GocuiEvent{ch(' '), ch('f'), ch('f')},
+			expectedPressed: []string{"leader f f"},
+			expectedPending: []string{" ", " f", ""},
+		},
+		{
+			name:            "shorter sequence fires when the longer one is abandoned",
+			events:          []GocuiEvent{ch(' '), ch('f'), ch('j')},
+			expectedPressed: []string{"leader f", "j"},
+			expectedPending: []string{" ", " f", ""},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pressed = []string{}
+			pending = []string{}
+			keys(test.events...)
+			assert.EqualValues(t, test.expectedPressed, pressed)
+			assert.EqualValues(t, test.expectedPending, pending)
+			assert.Empty(t, g.PendingKeySequence())
+		})
+	}
+
+	t.Run("timeout", func(t *testing.T) {
+		g.KeySequenceTimeout = time.Millisecond
+		pressed = []string{}
+		keys(ch('g'))
+		assert.EqualValues(t, []string{}, pressed)
+
+		ev := <-g.userEvents
+		assert.NoError(t, ev.f(g))
+		assert.EqualValues(t, []string{"g"}, pressed)
+		assert.Empty(t, g.PendingKeySequence())
+	})
+
+	t.Run("editable views", func(t *testing.T) {
+		g.KeySequenceTimeout = 0
+		v, _ := g.View("main")
+		v.Editable = true
+		defer func() { v.Editable = false }()
+
+		pressed = []string{}
+		keys(ch(' '), key(KeyCtrlX), key(KeyCtrlS))
+		assert.EqualValues(t, []string{"save"}, pressed)
+		assert.EqualValues(t, " ", v.TextArea.GetContent())
+	})
+}
+
+func TestSequenceKeybindingsSetManager(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 40, Height: 20})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	_, _ = g.SetView("main", 0, 0, 10, 10, 0)
+	_, _ = g.SetCurrentView("main")
+	pressed := 0
+	assert.NoError(t, g.SetSequenceKeybinding("", []KeyPress{{Key: KeyCtrlX}, {Key: KeyCtrlS}}, func(*Gui, *View) error {
+		pressed++
+		return nil
+	}))
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Key: KeyCtrlX}))
+	assert.Len(t, g.PendingKeySequence(), 1)
+
+	// a new manager drops the sequence keybindings and the pending sequence
+	g.SetManagerFunc(func(*Gui) error { return nil })
+	assert.Empty(t, g.PendingKeySequence())
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Key: KeyCtrlX}))
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Key: KeyCtrlS}))
+	assert.Equal(t, 0, pressed)
+}
+
+func TestSequenceKeybindingsPopModal(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 40, Height: 20})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	_, _ = g.SetView("main", 0, 0, 39, 19, 0)
+	_, _ = g.SetCurrentView("main")
+	pressed := 0
+	modal := &Modal{Name: "confirm", Width: 20, Height: 6, OnCreate: func(*View) error {
+		return g.SetSequenceKeybinding("confirm", []KeyPress{{Key: 'y'}, {Key: 'y'}}, func(*Gui, *View) error {
+			pressed++
+			return nil
+		})
+	}}
+	_, err = g.PushModal(modal)
+	assert.NoError(t, err)
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Ch: 'y'}))
+	assert.Len(t, g.PendingKeySequence(), 1)
+
+	// popping the modal drops its sequence keybindings and the pending
+	// sequence
+	assert.NoError(t, g.PopModal())
+	assert.Empty(t, g.PendingKeySequence())
+	assert.Empty(t, g.sequenceBindings)
+
+	_, err = g.PushModal(&Modal{Name: "confirm", Width: 20, Height: 6})
+	assert.NoError(t, err)
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Ch: 'y'}))
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Ch: 'y'}))
+	assert.Equal(t, 0, pressed)
+
+	// as does deleting all the keybindings
+	assert.NoError(t, g.PopModal())
+	_, err = g.PushModal(modal)
+	assert.NoError(t, err)
+	assert.NoError(t, g.onKey(&GocuiEvent{Type: eventKey, Ch: 'y'}))
+	g.DeleteAllKeybindings()
+	assert.Empty(t, g.PendingKeySequence())
+	assert.Empty(t, g.sequenceBindings)
+}
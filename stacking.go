@@ -0,0 +1,86 @@
+package gocui
+
+import (
+	"sort"
+
+	"github.com/go-errors/errors"
+)
+
+// Layer is a group of views that are stacked together. Views of a higher layer
+// are always drawn on top of, and get mouse events before, those of a lower
+// one, whatever order they were created in.
+type Layer int
+
+const (
+	// LayerBase is the layer of regular views.
+	LayerBase Layer = iota
+	// LayerPopup is for popups like menus and completion lists.
+	LayerPopup
+	// LayerTooltip is for tooltips, which show on top of popups.
+	LayerTooltip
+	// LayerModal is for modal dialogs, which show on top of everything.
+	LayerModal
+)
+
+// sortViews stacks the views by layer and z-index. The sort is stable, so
+// views with the same layer and z-index keep their relative order. The caller
+// must hold the views mutex.
+func (g *Gui) sortViews() {
+	sort.SliceStable(g.views, func(i, j int) bool {
+		a, b := g.views[i], g.views[j]
+		if a.Layer != b.Layer {
+			return a.Layer < b.Layer
+		}
+		return a.ZIndex < b.ZIndex
+	})
+}
+
+// SetViewLayer moves the view with the given name to a layer.
+func (g *Gui) SetViewLayer(name string, layer Layer) (*View, error) {
+	g.Mutexes.ViewsMutex.Lock()
+	defer g.Mutexes.ViewsMutex.Unlock()
+
+	for _, v := range g.views {
+		if v.name == name {
+			v.Layer = layer
+			g.sortViews()
+			return v, nil
+		}
+	}
+	return nil, errors.Wrap(ErrUnknownView, 0)
+}
+
+// SetViewZIndex sets the z-index of the view with the given name within its
+// layer.
+func (g *Gui) SetViewZIndex(name string, z int) (*View, error) {
+	g.Mutexes.ViewsMutex.Lock()
+	defer g.Mutexes.ViewsMutex.Unlock()
+
+	for _, v := range g.views {
+		if v.name == name {
+			v.ZIndex = z
+			g.sortViews()
+			return v, nil
+		}
+	}
+	return nil, errors.Wrap(ErrUnknownView, 0)
+}
+
+// zIndexBounds returns the lowest and highest z-index of the views of the
+// given layer, other than skip.
+func (g *Gui) zIndexBounds(layer Layer, skip *View) (lowest int, highest int, found bool) {
+	for _, v := range g.views {
+		if v == skip || v.Layer != layer {
+			continue
+		}
+		if !found || v.ZIndex < lowest {
+			lowest = v.ZIndex
+		}
+		if !found || v.ZIndex > highest {
+			highest = v.ZIndex
+		}
+		found = true
+	}
+
+	return lowest, highest, found
+}
@@ -0,0 +1,69 @@
+package gocui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStacking(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 40, Height: 20})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	names := func() []string {
+		result := []string{}
+		for _, v := range g.Views() {
+			result = append(result, v.Name())
+		}
+		return result
+	}
+
+	_, _ = g.SetView("popup", 5, 5, 15, 10, 0)
+	_, err = g.SetViewLayer("popup", LayerPopup)
+	assert.NoError(t, err)
+
+	// views created later in a lower layer stay beneath the popup
+	_, _ = g.SetView("a", 0, 0, 20, 15, 0)
+	_, _ = g.SetView("b", 0, 0, 20, 15, 0)
+	assert.EqualValues(t, []string{"a", "b", "popup"}, names())
+
+	v, err := g.VisibleViewByPosition(8, 7)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "popup", v.Name())
+
+	// running the layout again doesn't change the order
+	_, _ = g.SetView("popup", 5, 5, 15, 10, 0)
+	_, _ = g.SetView("a", 0, 0, 20, 15, 0)
+	assert.EqualValues(t, []string{"a", "b", "popup"}, names())
+
+	// moving views on top stays within their layer
+	_, err = g.SetViewOnTop("a")
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"b", "a", "popup"}, names())
+	_, err = g.SetViewOnBottom("a")
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"a", "b", "popup"}, names())
+
+	// z-indices order the views within a layer
+	_, err = g.SetViewZIndex("a", 1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"b", "a", "popup"}, names())
+	v, err = g.VisibleViewByPosition(1, 1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "a", v.Name())
+
+	// a view set on top of another one joins its layer
+	assert.NoError(t, g.SetViewOnTopOf("b", "popup"))
+	assert.EqualValues(t, []string{"a", "popup", "b"}, names())
+	b, err := g.View("b")
+	assert.NoError(t, err)
+	assert.EqualValues(t, LayerPopup, b.Layer)
+
+	// changes to the fields are picked up by hit testing
+	v.Layer = LayerModal
+	v, err = g.VisibleViewByPosition(8, 7)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "a", v.Name())
+	assert.EqualValues(t, []string{"popup", "b", "a"}, names())
+}
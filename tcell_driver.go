@@ -5,6 +5,8 @@
 package gocui
 
 import (
+	"time"
+
 	"github.com/gdamore/tcell/v2"
 	"github.com/mattn/go-runewidth"
 )
@@ -97,10 +99,10 @@ func (g *Gui) tcellInitSimulation(width int, height int) error {
 }
 
 // tcellSetCell sets the character cell at a given location to the given
-// content (rune) and attributes using provided OutputMode
-func tcellSetCell(x, y int, ch rune, fg, bg Attribute, outputMode OutputMode) {
+// content (rune and combining runes) and attributes using provided OutputMode
+func tcellSetCell(x, y int, ch rune, combining []rune, fg, bg Attribute, outputMode OutputMode) {
 	st := getTcellStyle(oldStyle{fg: fg, bg: bg, outputMode: outputMode})
-	Screen.SetContent(x, y, ch, nil, st)
+	Screen.SetContent(x, y, ch, combining, st)
 }
 
 // getTcellStyle creates tcell.Style from Attributes
@@ -151,26 +153,61 @@ type gocuiEventType uint8
 
 // GocuiEvent represents events like a keys, mouse actions, or window resize.
 //
-//	The 'Mod', 'Key' and 'Ch' fields are valid if 'Type' is 'eventKey'.
-//	The 'MouseX' and 'MouseY' fields are valid if 'Type' is 'eventMouse'.
+//	The 'Mod', 'Key', 'Ch' and 'KeyEvent' fields are valid if 'Type' is
+//	  'eventKey'.
+//	The 'MouseX' and 'MouseY' fields are valid if 'Type' is 'eventMouse' or
+//	  'eventMouseMove', and so are 'MouseEvent', 'ClickCount' (for presses)
+//	  and 'OriginX' and 'OriginY', the position of the press (for drags and
+//	  releases).
 //	The 'Width' and 'Height' fields are valid if 'Type' is 'eventResize'.
 //	The 'Focused' field is valid if 'Type' is 'eventFocus'.
 //	The 'Start' field is valid if 'Type' is 'eventPaste'. It is true for the
 //	  beginning of a paste operation, false for the end.
 //	The 'Err' field is valid if 'Type' is 'eventError'.
 type GocuiEvent struct {
-	Type    gocuiEventType
-	Mod     Modifier
-	Key     Key
-	Ch      rune
-	Width   int
-	Height  int
-	Err     error
-	MouseX  int
-	MouseY  int
-	Focused bool
-	Start   bool
-	N       int
+	Type     gocuiEventType
+	Mod      Modifier
+	Key      Key
+	Ch       rune
+	KeyEvent KeyEventType
+	Width    int
+	Height   int
+	Err      error
+	MouseX   int
+	MouseY   int
+	Focused  bool
+	Start    bool
+	N        int
+
+	MouseEvent MouseEventType
+	ClickCount int
+	OriginX    int
+	OriginY    int
+}
+
+// KeyEventType tells whether a key was pressed, repeated or released. Only
+// terminals supporting extended keys report repeats and releases; otherwise
+// every key event is a press.
+type KeyEventType uint8
+
+// Key event types.
+const (
+	KeyEventPress KeyEventType = iota
+	KeyEventRepeat
+	KeyEventRelease
+)
+
+// modifier returns the modifier that keybindings firing on the key event
+// have.
+func (t KeyEventType) modifier() Modifier {
+	switch t {
+	case KeyEventRepeat:
+		return ModRepeat
+	case KeyEventRelease:
+		return ModRelease
+	default:
+		return ModNone
+	}
 }
 
 // Event types.
@@ -193,13 +230,35 @@ const (
 	DRAGGING
 )
 
-var (
-	lastMouseKey tcell.ButtonMask = tcell.ButtonNone
-	lastMouseMod tcell.ModMask    = tcell.ModNone
-	dragState    int              = NOT_DRAGGING
-	lastX        int              = 0
-	lastY        int              = 0
-)
+// shiftedKeys maps keys to the placeholder keys we report when they're pressed
+// together with Shift, given that we drop the Shift modifier otherwise.
+var shiftedKeys = map[tcell.Key]tcell.Key{
+	tcell.KeyUp:   tcell.KeyF62,
+	tcell.KeyDown: tcell.KeyF63,
+}
+
+// selectionShiftedKeys are like shiftedKeys, for the keys that select text in
+// editable views. They're only remapped if NewGuiOpts.ShiftSelectKeys is set,
+// so that bindings of e.g. KeyArrowLeft still fire when Shift is held.
+var selectionShiftedKeys = map[tcell.Key]tcell.Key{
+	tcell.KeyLeft:  tcell.KeyF55,
+	tcell.KeyRight: tcell.KeyF54,
+	tcell.KeyHome:  tcell.KeyF53,
+	tcell.KeyEnd:   tcell.KeyF52,
+}
+
+// shiftedKey returns the placeholder key we report when k is pressed together
+// with Shift, if any.
+func (g *Gui) shiftedKey(k tcell.Key) (tcell.Key, bool) {
+	if shiftedKey, ok := shiftedKeys[k]; ok {
+		return shiftedKey, true
+	}
+	if g.shiftSelectKeys {
+		shiftedKey, ok := selectionShiftedKeys[k]
+		return shiftedKey, ok
+	}
+	return 0, false
+}
 
 // this wrapper struct has public keys so we can easily serialize/deserialize to JSON
 type TcellKeyEventWrapper struct {
@@ -267,6 +326,32 @@ func (wrapper TcellResizeEventWrapper) toTcellEvent() tcell.Event {
 
 // pollEvent get tcell.Event and transform it into gocuiEvent
 func (g *Gui) pollEvent() GocuiEvent {
+	for {
+		tev, replayed := g.nextTcellEvent()
+		if g.extendedKeys != nil && !replayed {
+			if ev, ok := g.extendedKeys.event(tev); ok {
+				if ev.Type == eventNone {
+					continue
+				}
+				return ev
+			}
+		}
+
+		return g.tcellEvent(tev)
+	}
+}
+
+// nextTcellEvent returns the next event from tcell, or from the recording
+// being played. It returns true if the event is a key that the decoder of
+// extended keys gave back, because it turned out not to be part of an escape
+// sequence.
+func (g *Gui) nextTcellEvent() (tcell.Event, bool) {
+	if g.extendedKeys != nil && len(g.extendedKeys.replay) > 0 {
+		tev := g.extendedKeys.replay[0]
+		g.extendedKeys.replay = g.extendedKeys.replay[1:]
+		return tev, true
+	}
+
 	var tev tcell.Event
 	if g.playRecording {
 		select {
@@ -281,6 +366,11 @@ func (g *Gui) pollEvent() GocuiEvent {
 		tev = Screen.PollEvent()
 	}
 
+	return tev, false
+}
+
+// tcellEvent transforms a tcell.Event into a gocuiEvent
+func (g *Gui) tcellEvent(tev tcell.Event) GocuiEvent {
 	switch tev := tev.(type) {
 	case *tcell.EventInterrupt:
 		return GocuiEvent{Type: eventInterrupt}
@@ -305,25 +395,29 @@ func (g *Gui) pollEvent() GocuiEvent {
 			mod = 0
 			ch = rune(0)
 			k = tcell.KeyCtrlSpace
-		} else if mod == tcell.ModShift && k == tcell.KeyUp {
-			mod = 0
-			ch = rune(0)
-			k = tcell.KeyF62
-		} else if mod == tcell.ModShift && k == tcell.KeyDown {
+		} else if g.extendedKeys != nil {
+			// with extended keys, keys are reported as they are with all their
+			// modifiers, except for runes and control keys, which include Ctrl
+			// and Shift themselves. Other keys, like arrows and function keys,
+			// keep them so that e.g. Ctrl+Up and Shift+F5 can be bound
+			if k <= 32 || k == tcell.KeyDEL {
+				mod &^= tcell.ModCtrl | tcell.ModShift
+			}
+		} else if shiftedKey, ok := g.shiftedKey(k); ok && mod == tcell.ModShift {
 			mod = 0
 			ch = rune(0)
-			k = tcell.KeyF63
-		} else if mod == tcell.ModCtrl || mod == tcell.ModShift {
-			// remove Ctrl or Shift if specified
-			// - shift - will be translated to the final code of rune
-			// - ctrl  - is translated in the key
-			mod = 0
+			k = shiftedKey
 		} else if mod == tcell.ModAlt && k == tcell.KeyEnter {
 			// for the sake of convenience I'm having a KeyAltEnter key. I will likely
 			// regret this laziness in the future. We're arbitrarily mapping that to tcell's
 			// KeyF64.
 			mod = 0
 			k = tcell.KeyF64
+		} else if mod == tcell.ModCtrl || mod == tcell.ModShift {
+			// remove Ctrl or Shift if specified
+			// - shift - will be translated to the final code of rune
+			// - ctrl  - is translated in the key
+			mod = 0
 		}
 
 		return GocuiEvent{
@@ -334,88 +428,7 @@ func (g *Gui) pollEvent() GocuiEvent {
 		}
 	case *tcell.EventMouse:
 		x, y := tev.Position()
-		button := tev.Buttons()
-		mouseKey := MouseRelease
-		mouseMod := ModNone
-		// process mouse wheel
-		if button&tcell.WheelUp != 0 {
-			mouseKey = MouseWheelUp
-		}
-		if button&tcell.WheelDown != 0 {
-			mouseKey = MouseWheelDown
-		}
-		if button&tcell.WheelLeft != 0 {
-			mouseKey = MouseWheelLeft
-		}
-		if button&tcell.WheelRight != 0 {
-			mouseKey = MouseWheelRight
-		}
-
-		wheeling := mouseKey == MouseWheelUp || mouseKey == MouseWheelDown || mouseKey == MouseWheelLeft || mouseKey == MouseWheelRight
-
-		// process button events (not wheel events)
-		button &= tcell.ButtonMask(0xff)
-		if button != tcell.ButtonNone && lastMouseKey == tcell.ButtonNone {
-			lastMouseKey = button
-			lastMouseMod = tev.Modifiers()
-			switch button {
-			case tcell.ButtonPrimary:
-				mouseKey = MouseLeft
-				dragState = MAYBE_DRAGGING
-				lastX = x
-				lastY = y
-			case tcell.ButtonSecondary:
-				mouseKey = MouseRight
-			case tcell.ButtonMiddle:
-				mouseKey = MouseMiddle
-			default:
-			}
-		}
-
-		switch tev.Buttons() {
-		case tcell.ButtonNone:
-			if lastMouseKey != tcell.ButtonNone {
-				switch lastMouseKey {
-				case tcell.ButtonPrimary:
-					dragState = NOT_DRAGGING
-				case tcell.ButtonSecondary:
-				case tcell.ButtonMiddle:
-				default:
-				}
-				mouseMod = Modifier(lastMouseMod)
-				lastMouseMod = tcell.ModNone
-				lastMouseKey = tcell.ButtonNone
-			}
-		default:
-		}
-
-		if !wheeling {
-			switch dragState {
-			case NOT_DRAGGING:
-				return GocuiEvent{
-					Type:   eventMouseMove,
-					MouseX: x,
-					MouseY: y,
-				}
-			// if we haven't released the left mouse button and we've moved the cursor then we're dragging
-			case MAYBE_DRAGGING:
-				if x != lastX || y != lastY {
-					dragState = DRAGGING
-				}
-			case DRAGGING:
-				mouseMod = ModMotion
-				mouseKey = MouseLeft
-			}
-		}
-
-		return GocuiEvent{
-			Type:   eventMouse,
-			MouseX: x,
-			MouseY: y,
-			Key:    mouseKey,
-			Ch:     0,
-			Mod:    mouseMod,
-		}
+		return g.mouseState.event(x, y, tev.Buttons(), tev.Modifiers(), time.Now(), g.DoubleClickInterval)
 	case *tcell.EventFocus:
 		return GocuiEvent{
 			Type:    eventFocus,
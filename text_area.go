@@ -2,8 +2,6 @@ package gocui
 
 import (
 	"strings"
-
-	"github.com/mattn/go-runewidth"
 )
 
 const (
@@ -25,32 +23,70 @@ type TextArea struct {
 	clipboard      string
 	AutoWrap       bool
 	AutoWrapWidth  int
+
+	// Clipboard, if set, receives all killed and copied text, and is where
+	// Yank pastes from.
+	Clipboard Clipboard
+
+	// UndoLimit is the maximum number of undo steps that are kept. Zero means
+	// a default of 100; a negative value disables undo.
+	UndoLimit int
+
+	undoStack      []textAreaState
+	redoStack      []textAreaState
+	lastEditKind   editKind
+	lastEditCursor int
+	editDepth      int
+
+	// killRing holds the most recent kills, the latest one last
+	killRing       []string
+	continuingKill bool
+	yankStart      int
+	yankIndex      int
+
+	// the selection spans from selectionAnchor to the cursor
+	selecting       bool
+	selectionAnchor int
 }
 
+// AutoWrapContent inserts soft line breaks into content so that no line is
+// wider than autoWrapWidth cells, breaking at spaces where possible. Widths
+// are measured in grapheme clusters, so composed characters are never split.
 func AutoWrapContent(content []rune, autoWrapWidth int) ([]rune, []CursorMapping) {
 	estimatedNumberOfSoftLineBreaks := len(content) / autoWrapWidth
 	cursorMapping := make([]CursorMapping, 0, estimatedNumberOfSoftLineBreaks)
 	wrappedContent := make([]rune, 0, len(content)+estimatedNumberOfSoftLineBreaks)
 	startOfLine := 0
 	indexOfLastWhitespace := -1
-
-	for currentPos, r := range content {
+	// the widths of content[startOfLine:currentPos] and of
+	// content[startOfLine:indexOfLastWhitespace]
+	lineWidth := 0
+	lineWidthAtLastWhitespace := 0
+
+	currentPos := 0
+	for _, g := range graphemes(content) {
+		r := g.runes[len(g.runes)-1]
 		if r == '\n' {
-			wrappedContent = append(wrappedContent, content[startOfLine:currentPos+1]...)
-			startOfLine = currentPos + 1
+			wrappedContent = append(wrappedContent, content[startOfLine:currentPos+len(g.runes)]...)
+			startOfLine = currentPos + len(g.runes)
 			indexOfLastWhitespace = -1
+			lineWidth = 0
 		} else {
-			if r == ' ' {
-				indexOfLastWhitespace = currentPos + 1
-			} else if currentPos-startOfLine >= autoWrapWidth && indexOfLastWhitespace >= 0 {
+			if g.runes[0] == ' ' {
+				indexOfLastWhitespace = currentPos + len(g.runes)
+				lineWidthAtLastWhitespace = lineWidth + g.width
+			} else if lineWidth+g.width > autoWrapWidth && indexOfLastWhitespace >= 0 {
 				wrapAt := indexOfLastWhitespace
 				wrappedContent = append(wrappedContent, content[startOfLine:wrapAt]...)
 				wrappedContent = append(wrappedContent, '\n')
 				cursorMapping = append(cursorMapping, CursorMapping{wrapAt, len(wrappedContent)})
 				startOfLine = wrapAt
 				indexOfLastWhitespace = -1
+				lineWidth -= lineWidthAtLastWhitespace
 			}
+			lineWidth += g.width
 		}
+		currentPos += len(g.runes)
 	}
 
 	wrappedContent = append(wrappedContent, content[startOfLine:]...)
@@ -67,8 +103,19 @@ func (self *TextArea) autoWrapContent() {
 }
 
 func (self *TextArea) TypeRune(r rune) {
+	defer self.trackEdit(editTyping)()
+
+	if self.HasSelection() {
+		self.DeleteSelection()
+	}
+
 	if self.overwrite && !self.atEnd() {
-		self.content[self.cursor] = r
+		// overwrite the whole grapheme cluster under the cursor
+		next := nextGraphemeBoundary(self.content, self.cursor)
+		self.content = append(
+			self.content[:self.cursor],
+			append([]rune{r}, self.content[next:]...)...,
+		)
 	} else {
 		self.content = append(
 			self.content[:self.cursor],
@@ -81,38 +128,46 @@ func (self *TextArea) TypeRune(r rune) {
 }
 
 func (self *TextArea) BackSpaceChar() {
+	defer self.trackEdit(editDeleting)()
+
+	if self.HasSelection() {
+		self.DeleteSelection()
+		return
+	}
+
 	if self.cursor == 0 {
 		return
 	}
 
-	self.content = append(self.content[:self.cursor-1], self.content[self.cursor:]...)
+	prev := prevGraphemeBoundary(self.content, self.cursor)
+	self.content = append(self.content[:prev], self.content[self.cursor:]...)
 	self.autoWrapContent()
-	self.cursor--
+	self.cursor = prev
 }
 
 func (self *TextArea) DeleteChar() {
+	defer self.trackEdit(editDeleting)()
+
+	if self.HasSelection() {
+		self.DeleteSelection()
+		return
+	}
+
 	if self.atEnd() {
 		return
 	}
 
-	self.content = append(self.content[:self.cursor], self.content[self.cursor+1:]...)
+	next := nextGraphemeBoundary(self.content, self.cursor)
+	self.content = append(self.content[:self.cursor], self.content[next:]...)
 	self.autoWrapContent()
 }
 
 func (self *TextArea) MoveCursorLeft() {
-	if self.cursor == 0 {
-		return
-	}
-
-	self.cursor--
+	self.cursor = prevGraphemeBoundary(self.content, self.cursor)
 }
 
 func (self *TextArea) MoveCursorRight() {
-	if self.cursor == len(self.content) {
-		return
-	}
-
-	self.cursor++
+	self.cursor = nextGraphemeBoundary(self.content, self.cursor)
 }
 
 func (self *TextArea) MoveLeftWord() {
@@ -190,6 +245,8 @@ func (self *TextArea) atEnd() bool {
 }
 
 func (self *TextArea) DeleteToStartOfLine() {
+	defer self.trackEdit(editKilling)()
+
 	// copying vim's logic: if you're at the start of the line, you delete the newline
 	// character and go to the end of the previous line
 	if self.atLineStart() {
@@ -197,6 +254,7 @@ func (self *TextArea) DeleteToStartOfLine() {
 			return
 		}
 
+		self.kill("\n", true)
 		self.content = append(self.content[:self.cursor-1], self.content[self.cursor:]...)
 		self.cursor--
 		self.autoWrapContent()
@@ -214,19 +272,22 @@ func (self *TextArea) DeleteToStartOfLine() {
 	// otherwise, you delete everything up to the start of the current line, without
 	// deleting the newline character
 	newlineIndex := self.closestNewlineOnLeft()
-	self.clipboard = string(self.content[newlineIndex+1 : self.cursor])
+	self.kill(string(self.content[newlineIndex+1:self.cursor]), true)
 	self.content = append(self.content[:newlineIndex+1], self.content[self.cursor:]...)
 	self.autoWrapContent()
 	self.cursor = newlineIndex + 1
 }
 
 func (self *TextArea) DeleteToEndOfLine() {
+	defer self.trackEdit(editKilling)()
+
 	if self.atEnd() {
 		return
 	}
 
 	// if we're at the end of the line, delete just the newline character
 	if self.atLineEnd() {
+		self.kill("\n", false)
 		self.content = append(self.content[:self.cursor], self.content[self.cursor+1:]...)
 		self.autoWrapContent()
 		return
@@ -241,7 +302,7 @@ func (self *TextArea) DeleteToEndOfLine() {
 	}
 
 	lineEndIndex := self.closestNewlineOnRight()
-	self.clipboard = string(self.content[self.cursor:lineEndIndex])
+	self.kill(string(self.content[self.cursor:lineEndIndex]), false)
 	self.content = append(self.content[:self.cursor], self.content[lineEndIndex:]...)
 	self.autoWrapContent()
 }
@@ -329,7 +390,19 @@ func (self *TextArea) atSoftLineEnd() bool {
 		(len(self.wrappedContent) > wrappedCursor+1 && self.wrappedContent[wrappedCursor+1] == '\n')
 }
 
+func (self *TextArea) onFirstLine() bool {
+	_, y := self.GetCursorXY()
+	return y == 0
+}
+
+func (self *TextArea) onLastLine() bool {
+	_, y := self.GetCursorXY()
+	return y == strings.Count(self.GetContent(), "\n")
+}
+
 func (self *TextArea) BackSpaceWord() {
+	defer self.trackEdit(editKilling)()
+
 	if self.cursor == 0 {
 		return
 	}
@@ -353,13 +426,42 @@ func (self *TextArea) BackSpaceWord() {
 		}
 	}
 
-	self.clipboard = string(self.content[self.cursor:right])
+	self.kill(string(self.content[self.cursor:right]), true)
 	self.content = append(self.content[:self.cursor], self.content[right:]...)
 	self.autoWrapContent()
 }
 
 func (self *TextArea) Yank() {
-	self.TypeString(self.clipboard)
+	defer self.trackEdit(editYanking)()
+
+	text := self.getClipboard()
+	if len(self.killRing) == 0 || self.killRing[len(self.killRing)-1] != text {
+		// the text was copied somewhere else, so it's not in the ring yet
+		self.pushKill(text)
+	}
+	self.yankIndex = len(self.killRing) - 1
+
+	self.TypeString(text)
+	self.yankStart = self.cursor - len([]rune(text))
+}
+
+func (self *TextArea) setClipboard(text string) {
+	self.clipboard = text
+	if self.Clipboard != nil {
+		// nothing sensible we can do about an error here; we still have our
+		// own copy of the text, so yanking keeps working
+		_ = self.Clipboard.Copy(text)
+	}
+}
+
+func (self *TextArea) getClipboard() string {
+	if self.Clipboard != nil {
+		if text, err := self.Clipboard.Paste(); err == nil {
+			return text
+		}
+	}
+
+	return self.clipboard
 }
 
 func origCursorToWrappedCursor(origCursor int, cursorMapping []CursorMapping) int {
@@ -398,19 +500,28 @@ func (self *TextArea) GetCursorXY() (int, int) {
 	cursorX := 0
 	cursorY := 0
 	wrappedCursor := self.origCursorToWrappedCursor(self.cursor)
-	for _, r := range self.wrappedContent[0:wrappedCursor] {
-		if r == '\n' {
+	for _, g := range graphemes(self.wrappedContent[0:wrappedCursor]) {
+		if g.runes[len(g.runes)-1] == '\n' {
 			cursorY++
 			cursorX = 0
 		} else {
-			chWidth := runewidth.RuneWidth(r)
-			cursorX += chWidth
+			cursorX += g.width
 		}
 	}
 
 	return cursorX, cursorY
 }
 
+// GetCursor returns the cursor as a position in the unwrapped content.
+func (self *TextArea) GetCursor() int {
+	return self.cursor
+}
+
+// SetCursor moves the cursor to the given position in the unwrapped content.
+func (self *TextArea) SetCursor(cursor int) {
+	self.cursor = clampCursor(cursor, len(self.content))
+}
+
 // takes an x,y position and maps it to a 1D cursor position
 func (self *TextArea) SetCursor2D(x int, y int) {
 	if y < 0 {
@@ -421,7 +532,7 @@ func (self *TextArea) SetCursor2D(x int, y int) {
 	}
 
 	newCursor := 0
-	for _, r := range self.wrappedContent {
+	for _, g := range graphemes(self.wrappedContent) {
 		if x <= 0 && y == 0 {
 			self.cursor = self.wrappedCursorToOrigCursor(newCursor)
 			if self.wrappedContent[newCursor] == '\n' {
@@ -430,7 +541,7 @@ func (self *TextArea) SetCursor2D(x int, y int) {
 			return
 		}
 
-		if r == '\n' {
+		if g.runes[len(g.runes)-1] == '\n' {
 			if y == 0 {
 				self.cursor = self.wrappedCursorToOrigCursor(newCursor)
 				self.moveLeftFromSoftLineBreak()
@@ -438,11 +549,10 @@ func (self *TextArea) SetCursor2D(x int, y int) {
 			}
 			y--
 		} else if y == 0 {
-			chWidth := runewidth.RuneWidth(r)
-			x -= chWidth
+			x -= g.width
 		}
 
-		newCursor++
+		newCursor += len(g.runes)
 	}
 
 	// if we weren't able to run-down our arg, the user is trying to move out of
@@ -458,9 +568,13 @@ func (self *TextArea) Clear() {
 	self.content = []rune{}
 	self.wrappedContent = []rune{}
 	self.cursor = 0
+	self.selecting = false
+	self.clearUndoHistory()
 }
 
 func (self *TextArea) TypeString(str string) {
+	defer self.trackEdit(editOther)()
+
 	for _, r := range str {
 		self.TypeRune(r)
 	}
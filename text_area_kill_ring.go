@@ -0,0 +1,54 @@
+package gocui
+
+// the number of kills a TextArea remembers
+const killRingSize = 30
+
+// kill saves killed text to the kill ring and the clipboard. Text killed right
+// after a previous kill is joined with it (in front of it when killing
+// backwards), so that it can be yanked as a whole.
+func (self *TextArea) kill(text string, backwards bool) {
+	if !self.continuingKill || len(self.killRing) == 0 {
+		self.pushKill(text)
+		return
+	}
+
+	last := len(self.killRing) - 1
+	if backwards {
+		self.killRing[last] = text + self.killRing[last]
+	} else {
+		self.killRing[last] += text
+	}
+	self.setClipboard(self.killRing[last])
+}
+
+func (self *TextArea) pushKill(text string) {
+	if text == "" {
+		return
+	}
+
+	self.killRing = append(self.killRing, text)
+	if excess := len(self.killRing) - killRingSize; excess > 0 {
+		self.killRing = self.killRing[excess:]
+	}
+	self.setClipboard(text)
+}
+
+// YankPop replaces the text inserted by the preceding Yank or YankPop with the
+// kill before it, cycling around to the latest kill after the oldest one. It
+// does nothing unless the last edit was a yank.
+func (self *TextArea) YankPop() {
+	if self.lastEditKind != editYanking || self.cursor != self.lastEditCursor || len(self.killRing) < 2 {
+		return
+	}
+
+	defer self.trackEdit(editYanking)()
+
+	self.content = append(self.content[:self.yankStart], self.content[self.cursor:]...)
+	self.cursor = self.yankStart
+
+	self.yankIndex--
+	if self.yankIndex < 0 {
+		self.yankIndex = len(self.killRing) - 1
+	}
+	self.TypeString(self.killRing[self.yankIndex])
+}
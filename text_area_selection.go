@@ -0,0 +1,124 @@
+package gocui
+
+// StartSelection anchors a selection at the cursor, unless a selection is
+// already in progress. Moving the cursor afterwards extends the selection.
+func (self *TextArea) StartSelection() {
+	if self.selecting {
+		return
+	}
+
+	self.selecting = true
+	self.selectionAnchor = self.cursor
+}
+
+// ClearSelection drops the selection without touching the content.
+func (self *TextArea) ClearSelection() {
+	self.selecting = false
+}
+
+// HasSelection reports whether a non-empty region is selected.
+func (self *TextArea) HasSelection() bool {
+	return self.selecting && self.selectionAnchor != self.cursor
+}
+
+// SelectAll selects the whole content, leaving the cursor at the end.
+func (self *TextArea) SelectAll() {
+	self.Select(0, len(self.content))
+}
+
+// Select selects the text from start to end, which are positions in the
+// unwrapped content, leaving the cursor at end.
+func (self *TextArea) Select(start int, end int) {
+	self.selecting = true
+	self.selectionAnchor = clampCursor(start, len(self.content))
+	self.cursor = clampCursor(end, len(self.content))
+}
+
+func clampCursor(cursor int, length int) int {
+	return max(0, min(cursor, length))
+}
+
+// GetSelectionRange returns the start (inclusive) and end (exclusive) of the
+// selection as positions in the unwrapped content. Both are equal to the
+// cursor if nothing is selected.
+func (self *TextArea) GetSelectionRange() (int, int) {
+	if !self.selecting {
+		return self.cursor, self.cursor
+	}
+
+	return min(self.selectionAnchor, self.cursor), max(self.selectionAnchor, self.cursor)
+}
+
+// GetSelectedText returns the selected part of the content.
+func (self *TextArea) GetSelectedText() string {
+	start, end := self.GetSelectionRange()
+	return string(self.content[start:end])
+}
+
+// DeleteSelection removes the selected text and puts the cursor where it was.
+func (self *TextArea) DeleteSelection() {
+	defer self.trackEdit(editOther)()
+
+	start, end := self.GetSelectionRange()
+	self.content = append(self.content[:start], self.content[end:]...)
+	self.cursor = start
+	self.selecting = false
+	self.autoWrapContent()
+}
+
+// CopySelection puts the selected text on the kill ring and the clipboard.
+func (self *TextArea) CopySelection() {
+	if !self.HasSelection() {
+		return
+	}
+
+	self.pushKill(self.GetSelectedText())
+}
+
+// CutSelection puts the selected text on the kill ring and the clipboard, and
+// removes it.
+func (self *TextArea) CutSelection() {
+	if !self.HasSelection() {
+		return
+	}
+
+	self.pushKill(self.GetSelectedText())
+	self.DeleteSelection()
+}
+
+// ReplaceSelection replaces the selected text with the given string. If
+// nothing is selected, the string is inserted at the cursor.
+func (self *TextArea) ReplaceSelection(str string) {
+	defer self.trackEdit(editOther)()
+
+	if self.HasSelection() {
+		self.DeleteSelection()
+	}
+	self.TypeString(str)
+}
+
+// selectedRegion returns the start (inclusive) and end (exclusive) of the
+// selection as x/y positions in the wrapped content, where x counts grapheme
+// clusters like the cells of the view.
+func (self *TextArea) selectedRegion() (pos, pos, bool) {
+	if !self.HasSelection() {
+		return pos{}, pos{}, false
+	}
+
+	start, end := self.GetSelectionRange()
+	return self.wrappedPos(start), self.wrappedPos(end), true
+}
+
+func (self *TextArea) wrappedPos(origCursor int) pos {
+	p := pos{}
+	for _, g := range graphemes(self.wrappedContent[:self.origCursorToWrappedCursor(origCursor)]) {
+		if g.runes[len(g.runes)-1] == '\n' {
+			p.y++
+			p.x = 0
+		} else {
+			p.x++
+		}
+	}
+
+	return p
+}
@@ -330,7 +330,7 @@ func TestTextArea(t *testing.T) {
 			},
 			expectedContent:   "ab",
 			expectedCursor:    2,
-			expectedClipboard: "",
+			expectedClipboard: "\n",
 		},
 		{
 			actions: func(textarea *TextArea) {
@@ -651,7 +651,7 @@ func TestTextArea(t *testing.T) {
 			},
 			expectedContent:   "abcdef",
 			expectedCursor:    3,
-			expectedClipboard: "",
+			expectedClipboard: "\n",
 		},
 		{
 			actions: func(textarea *TextArea) {
@@ -723,6 +723,13 @@ func TestGetCursorXY(t *testing.T) {
 			expectedX: 4,
 			expectedY: 0,
 		},
+		{
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("e\u0301👩\u200d👩\u200d👧🇩🇪")
+			},
+			expectedX: 5,
+			expectedY: 0,
+		},
 	}
 
 	for _, test := range tests {
@@ -791,6 +798,27 @@ func Test_AutoWrapContent(t *testing.T) {
 			expectedWrappedContent: "abc def \nghi jkl \nmno\npqr stu \nvwx yz\n",
 			expectedCursorMapping:  []CursorMapping{{8, 9}, {16, 18}, {28, 31}},
 		},
+		{
+			name:                   "wide characters take up two cells",
+			content:                "漢字 漢字 漢字",
+			autoWrapWidth:          8,
+			expectedWrappedContent: "漢字 \n漢字 \n漢字",
+			expectedCursorMapping:  []CursorMapping{{3, 4}, {6, 8}},
+		},
+		{
+			name:                   "a wide character that doesn't fit wraps",
+			content:                "abcdef 漢",
+			autoWrapWidth:          8,
+			expectedWrappedContent: "abcdef \n漢",
+			expectedCursorMapping:  []CursorMapping{{7, 8}},
+		},
+		{
+			name:                   "combining characters take up no cells",
+			content:                "e\u0301e\u0301e\u0301 xyz",
+			autoWrapWidth:          7,
+			expectedWrappedContent: "e\u0301e\u0301e\u0301 xyz",
+			expectedCursorMapping:  []CursorMapping{},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -805,7 +833,7 @@ func Test_AutoWrapContent(t *testing.T) {
 			// As a sanity check, run through all runes of the original content,
 			// convert the cursor to the wrapped cursor, and check that the rune
 			// in the wrapped content at that position is the same:
-			for i, r := range tt.content {
+			for i, r := range []rune(tt.content) {
 				wrappedIndex := origCursorToWrappedCursor(i, cursorMapping)
 				if r != wrappedContent[wrappedIndex] {
 					t.Errorf("Runes in orig content and wrapped content don't match at %d: expected %v, got %v", i, r, wrappedContent[wrappedIndex])
@@ -821,3 +849,565 @@ func Test_AutoWrapContent(t *testing.T) {
 		})
 	}
 }
+
+func TestTextAreaUndoRedo(t *testing.T) {
+	tests := []struct {
+		name            string
+		actions         func(*TextArea)
+		expectedContent string
+		expectedCursor  int
+	}{
+		{
+			name: "undo with empty history",
+			actions: func(textarea *TextArea) {
+				textarea.Undo()
+			},
+			expectedContent: "",
+			expectedCursor:  0,
+		},
+		{
+			name: "consecutive typing is undone in one step",
+			actions: func(textarea *TextArea) {
+				textarea.TypeRune('a')
+				textarea.TypeRune('b')
+				textarea.TypeRune('c')
+				textarea.Undo()
+			},
+			expectedContent: "",
+			expectedCursor:  0,
+		},
+		{
+			name: "moving the cursor starts a new undo step",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("ac")
+				textarea.MoveCursorLeft()
+				textarea.TypeRune('b')
+				textarea.TypeRune('b')
+				textarea.Undo()
+			},
+			expectedContent: "ac",
+			expectedCursor:  1,
+		},
+		{
+			name: "consecutive deletions are undone in one step",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("abc")
+				textarea.BackSpaceChar()
+				textarea.BackSpaceChar()
+				textarea.Undo()
+			},
+			expectedContent: "abc",
+			expectedCursor:  3,
+		},
+		{
+			name: "typing after deleting starts a new undo step",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("abc")
+				textarea.BackSpaceChar()
+				textarea.TypeRune('d')
+				textarea.Undo()
+			},
+			expectedContent: "ab",
+			expectedCursor:  2,
+		},
+		{
+			name: "no-op edits are not recorded",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("abc")
+				textarea.DeleteChar()
+				textarea.Undo()
+			},
+			expectedContent: "",
+			expectedCursor:  0,
+		},
+		{
+			name: "undo restores the cursor of a kill",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("abc def")
+				textarea.GoToStartOfLine()
+				textarea.MoveRightWord()
+				textarea.DeleteToEndOfLine()
+				textarea.Undo()
+			},
+			expectedContent: "abc def",
+			expectedCursor:  3,
+		},
+		{
+			name: "redo",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("abc")
+				textarea.BackSpaceWord()
+				textarea.Undo()
+				textarea.Redo()
+			},
+			expectedContent: "",
+			expectedCursor:  0,
+		},
+		{
+			name: "editing clears redo history",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("abc")
+				textarea.Undo()
+				textarea.TypeRune('x')
+				textarea.Redo()
+			},
+			expectedContent: "x",
+			expectedCursor:  1,
+		},
+		{
+			name: "undo limit drops the oldest steps",
+			actions: func(textarea *TextArea) {
+				textarea.UndoLimit = 2
+				textarea.TypeString("a")
+				textarea.TypeString("b")
+				textarea.TypeString("c")
+				textarea.Undo()
+				textarea.Undo()
+				textarea.Undo()
+			},
+			expectedContent: "a",
+			expectedCursor:  1,
+		},
+		{
+			name: "negative undo limit disables undo",
+			actions: func(textarea *TextArea) {
+				textarea.UndoLimit = -1
+				textarea.TypeString("abc")
+				textarea.Undo()
+			},
+			expectedContent: "abc",
+			expectedCursor:  3,
+		},
+		{
+			name: "overwrite mode",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("abc")
+				textarea.MoveCursorLeft()
+				textarea.MoveCursorLeft()
+				textarea.ToggleOverwrite()
+				textarea.TypeRune('x')
+				textarea.Undo()
+			},
+			expectedContent: "abc",
+			expectedCursor:  1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			textarea := &TextArea{}
+			test.actions(textarea)
+			assert.EqualValues(t, test.expectedContent, textarea.GetContent())
+			assert.EqualValues(t, test.expectedCursor, textarea.cursor)
+		})
+	}
+}
+
+func TestTextAreaSelection(t *testing.T) {
+	tests := []struct {
+		name              string
+		actions           func(*TextArea)
+		expectedContent   string
+		expectedCursor    int
+		expectedSelection string
+		expectedClipboard string
+	}{
+		{
+			name: "moving after starting a selection selects text",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("abc def")
+				textarea.StartSelection()
+				textarea.MoveLeftWord()
+			},
+			expectedContent:   "abc def",
+			expectedCursor:    4,
+			expectedSelection: "def",
+		},
+		{
+			name: "selection across lines",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("abc\ndef")
+				textarea.MoveCursorLeft()
+				textarea.StartSelection()
+				textarea.MoveCursorUp()
+			},
+			expectedContent:   "abc\ndef",
+			expectedCursor:    2,
+			expectedSelection: "c\nde",
+		},
+		{
+			name: "clearing the selection",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("abc")
+				textarea.StartSelection()
+				textarea.MoveCursorLeft()
+				textarea.ClearSelection()
+			},
+			expectedContent:   "abc",
+			expectedCursor:    2,
+			expectedSelection: "",
+		},
+		{
+			name: "typing replaces the selection",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("abc def")
+				textarea.StartSelection()
+				textarea.MoveLeftWord()
+				textarea.TypeRune('x')
+			},
+			expectedContent:   "abc x",
+			expectedCursor:    5,
+			expectedSelection: "",
+		},
+		{
+			name: "backspace deletes the selection only",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("abcd")
+				textarea.MoveCursorLeft()
+				textarea.StartSelection()
+				textarea.MoveCursorLeft()
+				textarea.MoveCursorLeft()
+				textarea.BackSpaceChar()
+			},
+			expectedContent:   "ad",
+			expectedCursor:    1,
+			expectedSelection: "",
+		},
+		{
+			name: "cut",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("abcd")
+				textarea.GoToStartOfLine()
+				textarea.MoveCursorRight()
+				textarea.StartSelection()
+				textarea.MoveCursorRight()
+				textarea.MoveCursorRight()
+				textarea.CutSelection()
+			},
+			expectedContent:   "ad",
+			expectedCursor:    1,
+			expectedSelection: "",
+			expectedClipboard: "bc",
+		},
+		{
+			name: "copy",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("abcd")
+				textarea.SelectAll()
+				textarea.CopySelection()
+			},
+			expectedContent:   "abcd",
+			expectedCursor:    4,
+			expectedSelection: "abcd",
+			expectedClipboard: "abcd",
+		},
+		{
+			name: "replace",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("abcd")
+				textarea.SelectAll()
+				textarea.ReplaceSelection("xy")
+			},
+			expectedContent:   "xy",
+			expectedCursor:    2,
+			expectedSelection: "",
+		},
+		{
+			name: "undo of a replacement restores the original text in one step",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("abcd")
+				textarea.SelectAll()
+				textarea.ReplaceSelection("xy")
+				textarea.Undo()
+			},
+			expectedContent:   "abcd",
+			expectedCursor:    4,
+			expectedSelection: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			textarea := &TextArea{}
+			test.actions(textarea)
+			assert.EqualValues(t, test.expectedContent, textarea.GetContent())
+			assert.EqualValues(t, test.expectedCursor, textarea.cursor)
+			assert.EqualValues(t, test.expectedSelection, textarea.GetSelectedText())
+			assert.EqualValues(t, test.expectedClipboard, textarea.clipboard)
+		})
+	}
+}
+
+func TestTextAreaClipboard(t *testing.T) {
+	clipboard := &MemoryClipboard{}
+	textarea := &TextArea{Clipboard: clipboard}
+
+	textarea.TypeString("abc def")
+	textarea.BackSpaceWord()
+	text, err := clipboard.Paste()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "def", text)
+
+	// text copied elsewhere is what gets yanked
+	assert.NoError(t, clipboard.Copy("xyz"))
+	textarea.Yank()
+	assert.EqualValues(t, "abc xyz", textarea.GetContent())
+
+	textarea.SelectAll()
+	textarea.CopySelection()
+	text, err = clipboard.Paste()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "abc xyz", text)
+}
+
+func TestTextAreaKillRing(t *testing.T) {
+	tests := []struct {
+		name              string
+		actions           func(*TextArea)
+		expectedContent   string
+		expectedCursor    int
+		expectedClipboard string
+	}{
+		{
+			name: "successive forward kills are appended",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("abc\ndef")
+				textarea.MoveCursorUp()
+				textarea.GoToStartOfLine()
+				textarea.DeleteToEndOfLine()
+				textarea.DeleteToEndOfLine()
+				textarea.DeleteToEndOfLine()
+				textarea.Yank()
+			},
+			expectedContent:   "abc\ndef",
+			expectedCursor:    7,
+			expectedClipboard: "abc\ndef",
+		},
+		{
+			name: "a kill at the end of a line kills the newline",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("abc\ndef")
+				textarea.MoveCursorUp()
+				textarea.GoToStartOfLine()
+				textarea.DeleteToEndOfLine()
+				textarea.DeleteToEndOfLine()
+				textarea.Yank()
+			},
+			expectedContent:   "abc\ndef",
+			expectedCursor:    4,
+			expectedClipboard: "abc\n",
+		},
+		{
+			name: "a kill at the start of a line kills the newline",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("abc\ndef")
+				textarea.DeleteToStartOfLine()
+				textarea.DeleteToStartOfLine()
+				textarea.Yank()
+			},
+			expectedContent:   "abc\ndef",
+			expectedCursor:    7,
+			expectedClipboard: "\ndef",
+		},
+		{
+			name: "successive backward kills are prepended",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("abc def ghi")
+				textarea.BackSpaceWord()
+				textarea.BackSpaceWord()
+				textarea.Yank()
+			},
+			expectedContent:   "abc def ghi",
+			expectedCursor:    11,
+			expectedClipboard: "def ghi",
+		},
+		{
+			name: "mixed kills are joined",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("abc def")
+				textarea.MoveCursorLeft()
+				textarea.DeleteToEndOfLine()
+				textarea.DeleteToStartOfLine()
+			},
+			expectedContent:   "",
+			expectedCursor:    0,
+			expectedClipboard: "abc def",
+		},
+		{
+			name: "kills separated by other edits are not joined",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("abc def")
+				textarea.BackSpaceWord()
+				textarea.TypeRune('x')
+				textarea.BackSpaceWord()
+			},
+			expectedContent:   "abc ",
+			expectedCursor:    4,
+			expectedClipboard: "x",
+		},
+		{
+			name: "kills separated by cursor movement are not joined",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("abc def")
+				textarea.BackSpaceWord()
+				textarea.MoveCursorLeft()
+				textarea.BackSpaceWord()
+			},
+			expectedContent:   " ",
+			expectedCursor:    0,
+			expectedClipboard: "abc",
+		},
+		{
+			name: "yank pop cycles through older kills",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("one two three")
+				textarea.BackSpaceWord()
+				textarea.TypeRune('x')
+				textarea.BackSpaceWord()
+				textarea.TypeRune('y')
+				textarea.BackSpaceWord()
+				textarea.Yank()
+				textarea.YankPop()
+			},
+			expectedContent:   "one two x",
+			expectedCursor:    9,
+			expectedClipboard: "y",
+		},
+		{
+			name: "yank pop wraps around",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("one two")
+				textarea.BackSpaceWord()
+				textarea.TypeRune('x')
+				textarea.BackSpaceWord()
+				textarea.Yank()
+				textarea.YankPop()
+				textarea.YankPop()
+			},
+			expectedContent:   "one x",
+			expectedCursor:    5,
+			expectedClipboard: "x",
+		},
+		{
+			name: "yank pop does nothing unless preceded by a yank",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("one two")
+				textarea.BackSpaceWord()
+				textarea.TypeRune('x')
+				textarea.BackSpaceWord()
+				textarea.TypeRune('y')
+				textarea.YankPop()
+			},
+			expectedContent:   "one y",
+			expectedCursor:    5,
+			expectedClipboard: "x",
+		},
+		{
+			name: "undo after yank pop restores the previous yank",
+			actions: func(textarea *TextArea) {
+				textarea.TypeString("one two")
+				textarea.BackSpaceWord()
+				textarea.TypeRune('x')
+				textarea.BackSpaceWord()
+				textarea.Yank()
+				textarea.YankPop()
+				textarea.Undo()
+			},
+			expectedContent:   "one x",
+			expectedCursor:    5,
+			expectedClipboard: "x",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			textarea := &TextArea{}
+			test.actions(textarea)
+			assert.EqualValues(t, test.expectedContent, textarea.GetContent())
+			assert.EqualValues(t, test.expectedCursor, textarea.cursor)
+			assert.EqualValues(t, test.expectedClipboard, textarea.clipboard)
+		})
+	}
+}
+
+func TestTextAreaGraphemes(t *testing.T) {
+	// a letter with a combining accent, a ZWJ emoji sequence and a flag
+	const content = "ae\u0301👩\u200d👩\u200d👧🇩🇪z"
+
+	tests := []struct {
+		name            string
+		actions         func(*TextArea)
+		expectedContent string
+		expectedCursor  int
+	}{
+		{
+			name: "move left",
+			actions: func(textarea *TextArea) {
+				textarea.MoveCursorLeft()
+				textarea.MoveCursorLeft()
+			},
+			expectedContent: content,
+			expectedCursor:  8,
+		},
+		{
+			name: "move right",
+			actions: func(textarea *TextArea) {
+				textarea.SetCursor(0)
+				textarea.MoveCursorRight()
+				textarea.MoveCursorRight()
+				textarea.MoveCursorRight()
+			},
+			expectedContent: content,
+			expectedCursor:  8,
+		},
+		{
+			name: "backspace",
+			actions: func(textarea *TextArea) {
+				textarea.MoveCursorLeft()
+				textarea.BackSpaceChar()
+				textarea.BackSpaceChar()
+			},
+			expectedContent: "ae\u0301z",
+			expectedCursor:  3,
+		},
+		{
+			name: "delete",
+			actions: func(textarea *TextArea) {
+				textarea.SetCursor(1)
+				textarea.DeleteChar()
+				textarea.DeleteChar()
+			},
+			expectedContent: "a🇩🇪z",
+			expectedCursor:  1,
+		},
+		{
+			name: "overwrite",
+			actions: func(textarea *TextArea) {
+				textarea.SetCursor(1)
+				textarea.ToggleOverwrite()
+				textarea.TypeRune('x')
+			},
+			expectedContent: "ax👩\u200d👩\u200d👧🇩🇪z",
+			expectedCursor:  2,
+		},
+		{
+			name: "set cursor 2D",
+			actions: func(textarea *TextArea) {
+				// the family emoji takes up cells 2 and 3
+				textarea.SetCursor2D(4, 0)
+			},
+			expectedContent: content,
+			expectedCursor:  8,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			textarea := &TextArea{}
+			textarea.TypeString(content)
+			test.actions(textarea)
+			assert.EqualValues(t, test.expectedContent, textarea.GetUnwrappedContent())
+			assert.EqualValues(t, test.expectedCursor, textarea.GetCursor())
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package gocui
+
+// the number of undo steps a TextArea keeps when UndoLimit is zero
+const defaultUndoLimit = 100
+
+// editKind classifies a change to a TextArea's content so that consecutive
+// changes of the same kind (e.g. typing a word) can be undone in one go.
+type editKind int
+
+const (
+	editOther editKind = iota
+	editTyping
+	editDeleting
+	editKilling
+	editYanking
+)
+
+type textAreaState struct {
+	content []rune
+	cursor  int
+}
+
+func (self *TextArea) undoLimit() int {
+	if self.UndoLimit == 0 {
+		return defaultUndoLimit
+	}
+
+	return self.UndoLimit
+}
+
+// trackEdit must be called at the start of every method that changes the
+// content, as in `defer self.trackEdit(editTyping)()`. The change becomes
+// undoable, merged with the previous one if both are typing or deleting and
+// the cursor hasn't moved in between. Nested calls are folded into the
+// outermost one, so e.g. TypeString is undone as a whole.
+func (self *TextArea) trackEdit(kind editKind) func() {
+	self.editDepth++
+	if self.editDepth > 1 {
+		return func() { self.editDepth-- }
+	}
+
+	before := self.state()
+	continuing := kind == self.lastEditKind && self.cursor == self.lastEditCursor
+	merge := (kind == editTyping || kind == editDeleting) && continuing && !self.HasSelection()
+	self.continuingKill = kind == editKilling && continuing
+
+	return func() {
+		self.editDepth--
+
+		if runesEqual(before.content, self.content) {
+			return
+		}
+
+		// positions in the old content are meaningless in the new one
+		self.selecting = false
+		self.lastEditKind = kind
+		self.lastEditCursor = self.cursor
+
+		if self.undoLimit() < 0 {
+			return
+		}
+
+		if !merge {
+			self.undoStack = append(self.undoStack, before)
+			if excess := len(self.undoStack) - self.undoLimit(); excess > 0 {
+				self.undoStack = self.undoStack[excess:]
+			}
+		}
+		self.redoStack = nil
+	}
+}
+
+func (self *TextArea) state() textAreaState {
+	return textAreaState{
+		content: append([]rune{}, self.content...),
+		cursor:  self.cursor,
+	}
+}
+
+func (self *TextArea) restoreState(state textAreaState) {
+	self.content = state.content
+	self.cursor = state.cursor
+	self.selecting = false
+	self.autoWrapContent()
+
+	// whatever is typed next shouldn't be merged into the edit we came from
+	self.lastEditKind = editOther
+}
+
+// Undo reverts the last change to the content and restores the cursor to
+// where it was before that change.
+func (self *TextArea) Undo() {
+	if len(self.undoStack) == 0 {
+		return
+	}
+
+	state := self.undoStack[len(self.undoStack)-1]
+	self.undoStack = self.undoStack[:len(self.undoStack)-1]
+	self.redoStack = append(self.redoStack, self.state())
+	self.restoreState(state)
+}
+
+// Redo reapplies the last change reverted by Undo.
+func (self *TextArea) Redo() {
+	if len(self.redoStack) == 0 {
+		return
+	}
+
+	state := self.redoStack[len(self.redoStack)-1]
+	self.redoStack = self.redoStack[:len(self.redoStack)-1]
+	self.undoStack = append(self.undoStack, self.state())
+	self.restoreState(state)
+}
+
+func (self *TextArea) clearUndoHistory() {
+	self.undoStack = nil
+	self.redoStack = nil
+	self.lastEditKind = editOther
+}
+
+func runesEqual(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
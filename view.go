@@ -11,7 +11,6 @@ import (
 	"strings"
 	"sync"
 	"unicode"
-	"unicode/utf8"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/mattn/go-runewidth"
@@ -80,6 +79,19 @@ type View struct {
 	// Visible specifies whether the view is visible.
 	Visible bool
 
+	// Layer and ZIndex determine how the views are stacked: views of a higher
+	// layer are on top of those of a lower one, and within a layer, views with
+	// a higher ZIndex are on top. Views with the same layer and ZIndex are
+	// stacked in the order they were created, or moved with SetViewOnTop and
+	// the like.
+	Layer  Layer
+	ZIndex int
+
+	// OnFocus and OnBlur, if set, are called when the view gets and loses
+	// the focus.
+	OnFocus func(*View) error
+	OnBlur  func(*View) error
+
 	// BgColor and FgColor allow to configure the background and foreground
 	// colors of the View.
 	BgColor, FgColor Attribute
@@ -102,6 +114,14 @@ type View struct {
 	// default.
 	Editor Editor
 
+	// History, if set, lets the user recall and search the input previously
+	// submitted in an editable view. See History.
+	History *History
+
+	// Completer, if set, offers completions of the input of an editable view.
+	// See Completer.
+	Completer Completer
+
 	// Overwrite enables or disables the overwrite mode of the view.
 	Overwrite bool
 
@@ -138,6 +158,12 @@ type View struct {
 	// view's x-origin will be ignored.
 	Wrap bool
 
+	// If Bidi is true, each line is displayed in the order given by the
+	// Unicode bidirectional algorithm, so that right-to-left scripts like
+	// Arabic and Hebrew read correctly. The content and the cursor position
+	// stay in logical order.
+	Bidi bool
+
 	// If Autoscroll is true, the View will automatically scroll down when the
 	// text overflows. If true the view's y-origin will be ignored.
 	Autoscroll bool
@@ -198,6 +224,18 @@ type View struct {
 
 	// number of spaces per \t character, defaults to 4
 	TabWidth int
+
+	// If Selectable is true, text can be selected with the mouse in a view
+	// that isn't editable: by dragging, double-clicking a word or
+	// triple-clicking a line.
+	Selectable bool
+
+	// OnCopy, if set, is called with the text selected with the mouse when
+	// the selection is done. Otherwise the text is put on the clipboard.
+	OnCopy func(*View, string) error
+
+	// the text selected with the mouse, if Selectable
+	mouseSelection *mouseSelection
 }
 
 type pos struct {
@@ -394,18 +432,35 @@ type viewLine struct {
 }
 
 type cell struct {
-	chr              rune
+	chr rune
+	// the runes following chr in the same grapheme cluster, e.g. combining
+	// accents or the rest of an emoji ZWJ sequence
+	combining        []rune
 	bgColor, fgColor Attribute
 	hyperlink        string
 }
 
+// runes returns the grapheme cluster displayed in the cell.
+func (c cell) runes() []rune {
+	return append([]rune{c.chr}, c.combining...)
+}
+
+// width returns the number of screen columns the cell takes up.
+func (c cell) width() int {
+	if len(c.combining) == 0 {
+		return runewidth.RuneWidth(c.chr)
+	}
+
+	return clusterWidth(c.runes())
+}
+
 type lineType []cell
 
 // String returns a string from a given cell slice.
 func (l lineType) String() string {
 	str := ""
 	for _, c := range l {
-		str += string(c.chr)
+		str += string(c.runes())
 	}
 	return str
 }
@@ -492,7 +547,7 @@ func (v *View) Name() string {
 // setRune sets a rune at the given point relative to the view. It applies the
 // specified colors, taking into account if the cell must be highlighted. Also,
 // it checks if the position is valid.
-func (v *View) setRune(x, y int, ch rune, fgColor, bgColor Attribute) {
+func (v *View) setRune(x, y int, ch rune, combining []rune, fgColor, bgColor Attribute) {
 	maxX, maxY := v.Size()
 	if x < 0 || x >= maxX || y < 0 || y >= maxY {
 		return
@@ -502,6 +557,7 @@ func (v *View) setRune(x, y int, ch rune, fgColor, bgColor Attribute) {
 		fgColor = v.FgColor
 		bgColor = v.BgColor
 		ch = v.Mask
+		combining = nil
 	} else if v.Highlight {
 		rangeSelectStart := v.cy
 		rangeSelectEnd := v.cy
@@ -544,7 +600,7 @@ func (v *View) setRune(x, y int, ch rune, fgColor, bgColor Attribute) {
 		ch = ' '
 	}
 
-	tcellSetCell(v.x0+x+1, v.y0+y+1, ch, fgColor, bgColor, v.outMode)
+	tcellSetCell(v.x0+x+1, v.y0+y+1, ch, combining, fgColor, bgColor, v.outMode)
 }
 
 func min(a, b int) int {
@@ -806,6 +862,12 @@ func (v *View) writeRunes(p []rune) {
 			finishLine()
 			v.wx = 0
 		default:
+			if v.continuesPrevCell(r) {
+				prev := &v.lines[v.wy][v.wx-1]
+				prev.combining = append(append([]rune{}, prev.combining...), r)
+				continue
+			}
+
 			truncateLine, cells := v.parseInput(r, v.wx, v.wy)
 			if cells == nil {
 				continue
@@ -827,6 +889,18 @@ func (v *View) writeRunes(p []rune) {
 	v.updateSearchPositions()
 }
 
+// continuesPrevCell reports whether r belongs to the grapheme cluster of the
+// cell just written, in which case it's added to that cell instead of getting
+// one of its own.
+func (v *View) continuesPrevCell(r rune) bool {
+	if v.ei.state != stateNone || r == '\t' || v.wx == 0 || v.wx > len(v.lines[v.wy]) {
+		return false
+	}
+
+	prev := v.lines[v.wy][v.wx-1]
+	return prev.chr != 0 && continuesGrapheme(prev.runes(), r)
+}
+
 // exported functions use the mutex. Non-exported functions are for internal use
 // and a calling function should use a mutex
 func (v *View) WriteString(s string) {
@@ -917,7 +991,7 @@ func (v *View) parseInput(ch rune, x int, _ int) (bool, []cell) {
 			v.ei.instructionRead()
 			cx := 0
 			for _, cell := range v.lines[v.wy][0:v.wx] {
-				cx += runewidth.RuneWidth(cell.chr)
+				cx += cell.width()
 			}
 			repeatCount = v.InnerWidth() - cx
 			ch = ' '
@@ -952,7 +1026,6 @@ func (v *View) parseInput(ch rune, x int, _ int) (bool, []cell) {
 // It returns the number of bytes read into p.
 // At EOF, err will be io.EOF.
 func (v *View) Read(p []byte) (n int, err error) {
-	buffer := make([]byte, utf8.UTFMax)
 	offset := 0
 	if v.readBuffer != nil {
 		copy(p, v.readBuffer)
@@ -966,13 +1039,14 @@ func (v *View) Read(p []byte) (n int, err error) {
 	}
 	for v.ry < len(v.lines) {
 		for v.rx < len(v.lines[v.ry]) {
-			count := utf8.EncodeRune(buffer, v.lines[v.ry][v.rx].chr)
-			copy(p[offset:], buffer[:count])
+			buffer := []byte(string(v.lines[v.ry][v.rx].runes()))
+			count := len(buffer)
+			copy(p[offset:], buffer)
 			v.rx++
 			newOffset := offset + count
 			if newOffset >= len(p) {
 				if newOffset > len(p) {
-					v.readBuffer = buffer[newOffset-len(p):]
+					v.readBuffer = buffer[count-(newOffset-len(p)):]
 				}
 				return len(p), nil
 			}
@@ -1104,7 +1178,7 @@ func (v *View) updateSearchPositions() {
 				if found {
 					result = append(result, SearchPosition{XStart: x, XEnd: x + searchStringWidth, Y: y})
 				}
-				x += runewidth.RuneWidth(c.chr)
+				x += c.width()
 			}
 			return result
 		}
@@ -1193,6 +1267,8 @@ func (v *View) draw() {
 	emptyCell := cell{chr: ' ', fgColor: ColorDefault, bgColor: ColorDefault}
 	var prevFgColor Attribute
 
+	selectionStart, selectionEnd, hasSelection := v.selectedRegion()
+
 	for y, vline := range v.viewLines[start:] {
 		if y >= maxY {
 			break
@@ -1204,6 +1280,19 @@ func (v *View) draw() {
 		x := -v.ox
 		cellIdx := 0
 
+		// with bidi, the cells are displayed in a different order than they are
+		// stored in; lineIdx is the index of the cell displayed at cellIdx
+		var layout *bidiLayout
+		if v.Bidi {
+			layout = newBidiLayout(vline.line)
+		}
+		lineIdx := func(cellIdx int) int {
+			if layout == nil || cellIdx >= len(layout.order) {
+				return cellIdx
+			}
+			return layout.order[cellIdx]
+		}
+
 		var c cell
 		for {
 			if x >= maxX {
@@ -1212,7 +1301,7 @@ func (v *View) draw() {
 
 			if x < 0 {
 				if cellIdx < len(vline.line) {
-					x += runewidth.RuneWidth(vline.line[cellIdx].chr)
+					x += vline.line[lineIdx(cellIdx)].width()
 					cellIdx++
 					continue
 				} else {
@@ -1227,7 +1316,10 @@ func (v *View) draw() {
 				c = emptyCell
 				c.fgColor = prevFgColor
 			} else {
-				c = vline.line[cellIdx]
+				c = vline.line[lineIdx(cellIdx)]
+				if layout != nil {
+					c.chr = layout.displayedRune(vline.line, lineIdx(cellIdx))
+				}
 				// capturing previous foreground colour so that if we're using the reverse
 				// attribute we honour the final character's colour and don't awkwardly switch
 				// to a new background colour for the remainder of the line
@@ -1245,12 +1337,16 @@ func (v *View) draw() {
 			if c.hyperlink != "" && !v.UnderlineHyperLinksOnlyOnHover {
 				fgColor |= AttrUnderline
 			}
+			if hasSelection && cellIdx < len(vline.line) &&
+				posInRegion(pos{x: vline.linesX + lineIdx(cellIdx), y: vline.linesY}, selectionStart, selectionEnd) {
+				fgColor |= AttrReverse
+			}
 
-			v.setRune(x, y, c.chr, fgColor, bgColor)
+			v.setRune(x, y, c.chr, c.combining, fgColor, bgColor)
 
 			// Not sure why the previous code was here but it caused problems
 			// when typing wide characters in an editor
-			x += runewidth.RuneWidth(c.chr)
+			x += c.width()
 			cellIdx++
 		}
 	}
@@ -1270,9 +1366,9 @@ func (v *View) refreshViewLinesIfNeeded() {
 				wrap = maxX
 			}
 
-			ls := lineWrap(line, wrap)
+			ls, offsets := lineWrapWithOffsets(line, wrap)
 			for j := range ls {
-				vline := viewLine{linesX: j, linesY: i, line: ls[j]}
+				vline := viewLine{linesX: offsets[j], linesY: i, line: ls[j]}
 
 				if lineIdx > len(v.viewLines)-1 {
 					v.viewLines = append(v.viewLines, vline)
@@ -1300,6 +1396,26 @@ func (v *View) viewLineLengthIgnoringTrailingBlankLines() int {
 	return 0
 }
 
+// selectedRegion returns the start (inclusive) and end (exclusive) of the
+// selected text, as positions in v.lines.
+func (v *View) selectedRegion() (pos, pos, bool) {
+	if v.Editable {
+		return v.TextArea.selectedRegion()
+	}
+	if v.mouseSelection != nil && v.mouseSelection.active {
+		start, end := v.mouseSelection.region(v)
+		return start, end, true
+	}
+
+	return pos{}, pos{}, false
+}
+
+func posInRegion(p pos, start pos, end pos) bool {
+	afterStart := p.y > start.y || (p.y == start.y && p.x >= start.x)
+	beforeEnd := p.y < end.y || (p.y == end.y && p.x < end.x)
+	return afterStart && beforeEnd
+}
+
 func (v *View) isPatternMatchedRune(x, y int) (bool, bool) {
 	for i, pos := range v.searcher.searchPositions {
 		adjustedY := y + v.oy
@@ -1352,7 +1468,7 @@ func (v *View) clearRunes() {
 	maxX, maxY := v.InnerSize()
 	for x := 0; x < maxX; x++ {
 		for y := 0; y < maxY; y++ {
-			tcellSetCell(v.x0+x+1, v.y0+y+1, ' ', v.FgColor, v.BgColor, v.outMode)
+			tcellSetCell(v.x0+x+1, v.y0+y+1, ' ', nil, v.FgColor, v.BgColor, v.outMode)
 		}
 	}
 }
@@ -1495,17 +1611,25 @@ func (v *View) SetHighlight(y int, on bool) {
 }
 
 func lineWrap(line []cell, columns int) [][]cell {
+	lines, _ := lineWrapWithOffsets(line, columns)
+	return lines
+}
+
+// lineWrapWithOffsets is like lineWrap, but also returns the index in the
+// original line at which each of the wrapped lines starts.
+func lineWrapWithOffsets(line []cell, columns int) ([][]cell, []int) {
 	if columns == 0 {
-		return [][]cell{line}
+		return [][]cell{line}, []int{0}
 	}
 
 	var n int
 	var offset int
 	lastWhitespaceIndex := -1
 	lines := make([][]cell, 0, 1)
+	offsets := make([]int, 0, 1)
 	for i := range line {
 		currChr := line[i].chr
-		rw := runewidth.RuneWidth(currChr)
+		rw := line[i].width()
 		n += rw
 		// if currChr == 'g' {
 		// 	panic(n)
@@ -1518,11 +1642,13 @@ func lineWrap(line []cell, columns int) [][]cell {
 				// way to distinguish between a clean break and a mid-word break, but
 				// I think it's worth it.
 				lines = append(lines, line[offset:i])
+				offsets = append(offsets, offset)
 				offset = i + 1
 				n = 0
 			} else if currChr == '-' {
 				// if the last character is hyphen and the width of line is equal to the columns
 				lines = append(lines, line[offset:i])
+				offsets = append(offsets, offset)
 				offset = i
 				n = rw
 			} else if lastWhitespaceIndex != -1 {
@@ -1534,15 +1660,17 @@ func lineWrap(line []cell, columns int) [][]cell {
 					// if break occurs at space, we'll omit the space
 					lines = append(lines, line[offset:lastWhitespaceIndex])
 				}
+				offsets = append(offsets, offset)
 				// Either way, continue *after* the break
 				offset = lastWhitespaceIndex + 1
 				n = 0
 				for _, c := range line[offset : i+1] {
-					n += runewidth.RuneWidth(c.chr)
+					n += c.width()
 				}
 			} else {
 				// in this case we're breaking mid-word
 				lines = append(lines, line[offset:i])
+				offsets = append(offsets, offset)
 				offset = i
 				n = rw
 			}
@@ -1553,7 +1681,8 @@ func lineWrap(line []cell, columns int) [][]cell {
 	}
 
 	lines = append(lines, line[offset:])
-	return lines
+	offsets = append(offsets, offset)
+	return lines, offsets
 }
 
 func linesToString(lines [][]cell) string {
@@ -1599,6 +1728,26 @@ func (v *View) GetClickedTabIndex(x int) int {
 	return -1
 }
 
+// onTitle tells whether the point (x, y) of the screen is on the title or
+// the tabs drawn on the top edge of the frame of the view.
+func (v *View) onTitle(x, y int) bool {
+	if !v.Frame || y != v.y0 {
+		return false
+	}
+
+	title := v.Title
+	if len(v.Tabs) > 0 {
+		title = strings.Join(v.Tabs, " - ")
+	}
+	width := runewidth.StringWidth(title)
+	if v.TitlePrefix != "" {
+		// the prefix is followed by a frame rune
+		width += runewidth.StringWidth(v.TitlePrefix) + 1
+	}
+
+	return x >= v.x0+2 && x < min(v.x0+2+width, v.x1-1)
+}
+
 func (v *View) SelectedLineIdx() int {
 	_, seletedLineIdx := v.SelectedPoint()
 	return seletedLineIdx
@@ -0,0 +1,221 @@
+package gocui
+
+import (
+	"strings"
+)
+
+// selectionUnit is what a mouse selection grows by.
+type selectionUnit int
+
+const (
+	selectChars selectionUnit = iota
+	selectWords
+	selectLines
+)
+
+// mouseSelection is the text selected with the mouse in a view that isn't
+// editable, between the positions of the press and of the pointer, in
+// v.lines.
+type mouseSelection struct {
+	unit   selectionUnit
+	anchor pos
+	cursor pos
+	// a single click doesn't select anything until the pointer is dragged
+	active bool
+}
+
+// linesPosition returns the position in v.lines of the cell under the
+// pointer, clamped to the content of the view.
+func (v *View) linesPosition(mx, my int) pos {
+	v.refreshViewLinesIfNeeded()
+	if len(v.viewLines) == 0 {
+		return pos{}
+	}
+
+	x, y := v.mousePosition(mx, my)
+	if y < 0 {
+		return pos{x: 0, y: v.viewLines[0].linesY}
+	}
+	if y >= len(v.viewLines) {
+		vline := v.viewLines[len(v.viewLines)-1]
+		return pos{x: vline.linesX + len(vline.line), y: vline.linesY}
+	}
+	vline := v.viewLines[y]
+	return pos{x: vline.linesX + cellIndex(vline.line, x), y: vline.linesY}
+}
+
+// cellIndex returns the index of the cell at a column of the line, where wide
+// characters take up two columns, or the length of the line for columns past
+// its end.
+func cellIndex(line []cell, x int) int {
+	col := 0
+	for i, c := range line {
+		col += c.width()
+		if x < col {
+			return i
+		}
+	}
+	return len(line)
+}
+
+// selectionUnitRange returns the start (inclusive) and end (exclusive) of
+// the character, word or line at the position.
+func (v *View) selectionUnitRange(p pos, unit selectionUnit) (pos, pos) {
+	switch unit {
+	case selectLines:
+		return pos{x: 0, y: p.y}, pos{x: 0, y: p.y + 1}
+	case selectWords:
+		if p.y < len(v.lines) && p.x < len(v.lines[p.y]) && !indexFunc(v.lines[p.y][p.x].chr) {
+			line := v.lines[p.y]
+			start, end := p.x, p.x
+			for start > 0 && !indexFunc(line[start-1].chr) {
+				start--
+			}
+			for end < len(line) && !indexFunc(line[end].chr) {
+				end++
+			}
+			return pos{x: start, y: p.y}, pos{x: end, y: p.y}
+		}
+	}
+	return p, pos{x: p.x + 1, y: p.y}
+}
+
+func posBefore(a pos, b pos) bool {
+	return a.y < b.y || (a.y == b.y && a.x < b.x)
+}
+
+// region returns the start (inclusive) and end (exclusive) of the selection,
+// which spans the units at the anchor and at the cursor.
+func (s *mouseSelection) region(v *View) (pos, pos) {
+	anchorStart, anchorEnd := v.selectionUnitRange(s.anchor, s.unit)
+	cursorStart, cursorEnd := v.selectionUnitRange(s.cursor, s.unit)
+	start, end := anchorStart, anchorEnd
+	if posBefore(cursorStart, start) {
+		start = cursorStart
+	}
+	if posBefore(end, cursorEnd) {
+		end = cursorEnd
+	}
+	return start, end
+}
+
+// startMouseSelection starts a selection at the pointer: a word for a double
+// click, a line for a triple click, and nothing yet for a single click.
+func (v *View) startMouseSelection(ev *GocuiEvent) {
+	v.writeMutex.Lock()
+	defer v.writeMutex.Unlock()
+
+	p := v.linesPosition(ev.MouseX, ev.MouseY)
+	s := &mouseSelection{anchor: p, cursor: p}
+	switch ev.ClickCount {
+	case 2:
+		s.unit, s.active = selectWords, true
+	case 3:
+		s.unit, s.active = selectLines, true
+	}
+	v.mouseSelection = s
+}
+
+// extendMouseSelection extends the selection to the pointer.
+func (v *View) extendMouseSelection(ev *GocuiEvent) {
+	v.writeMutex.Lock()
+	defer v.writeMutex.Unlock()
+
+	if v.mouseSelection == nil {
+		return
+	}
+	v.mouseSelection.cursor = v.linesPosition(ev.MouseX, ev.MouseY)
+	v.mouseSelection.active = true
+}
+
+// SelectedText returns the text selected with the mouse, or the selected text
+// of the text area for editable views.
+func (v *View) SelectedText() string {
+	if v.Editable {
+		return v.TextArea.GetSelectedText()
+	}
+
+	v.writeMutex.Lock()
+	defer v.writeMutex.Unlock()
+
+	if v.mouseSelection == nil || !v.mouseSelection.active {
+		return ""
+	}
+
+	start, end := v.mouseSelection.region(v)
+	lines := []string{}
+	for y := start.y; y <= end.y && y < len(v.lines); y++ {
+		line := v.lines[y]
+		x0, x1 := 0, len(line)
+		if y == start.y {
+			x0 = min(start.x, len(line))
+		}
+		if y == end.y {
+			x1 = min(end.x, len(line))
+		}
+		var b strings.Builder
+		for _, c := range line[x0:max(x0, x1)] {
+			if c.chr != 0 {
+				b.WriteString(string(c.runes()))
+			}
+		}
+		lines = append(lines, b.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ClearSelection drops the text selected with the mouse, or the selection
+// of the text area for editable views.
+func (v *View) ClearSelection() {
+	if v.Editable {
+		v.TextArea.ClearSelection()
+		return
+	}
+
+	v.writeMutex.Lock()
+	defer v.writeMutex.Unlock()
+
+	v.mouseSelection = nil
+}
+
+// copySelection passes the text selected with the mouse to the OnCopy hook
+// of the view, or else puts it on the clipboard.
+func (v *View) copySelection() error {
+	text := v.SelectedText()
+	if text == "" {
+		return nil
+	}
+	if v.OnCopy != nil {
+		return v.OnCopy(v, text)
+	}
+	if v.TextArea.Clipboard != nil {
+		return v.TextArea.Clipboard.Copy(text)
+	}
+	return nil
+}
+
+// onMouseSelection selects text in a selectable view that isn't editable, by
+// dragging with the left button, double-clicking a word or triple-clicking a
+// line. The selection is copied when the button is released.
+func (g *Gui) onMouseSelection(v *View, ev *GocuiEvent) error {
+	if v == nil || !v.Selectable || v.Editable || ev.Key != MouseLeft {
+		return nil
+	}
+
+	switch ev.MouseEvent {
+	case MouseEventPress:
+		if g.selectionView != nil && g.selectionView != v {
+			g.selectionView.ClearSelection()
+		}
+		g.selectionView = v
+		v.startMouseSelection(ev)
+		if ev.ClickCount > 1 {
+			return v.copySelection()
+		}
+	case MouseEventDragStart, MouseEventDragMove:
+		v.extendMouseSelection(ev)
+	case MouseEventDragEnd:
+		return v.copySelection()
+	}
+	return nil
+}
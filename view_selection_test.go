@@ -0,0 +1,137 @@
+package gocui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMouseSelection(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wrap     bool
+		originX  int
+		clicks   int
+		from     []int
+		to       []int
+		expected string
+	}{
+		{
+			name:     "drag",
+			from:     []int{3, 1},
+			to:       []int{3, 2},
+			expected: "llo world\nsec",
+		},
+		{
+			name:     "drag backwards",
+			from:     []int{3, 2},
+			to:       []int{3, 1},
+			expected: "llo world\nsec",
+		},
+		{
+			name:     "drag past the end of the content",
+			from:     []int{7, 1},
+			to:       []int{5, 9},
+			expected: "world\nsecond line",
+		},
+		{
+			name:     "double click",
+			clicks:   2,
+			from:     []int{8, 1},
+			expected: "world",
+		},
+		{
+			name:     "triple click",
+			clicks:   3,
+			from:     []int{8, 1},
+			expected: "hello world\n",
+		},
+		{
+			name:     "wrapped line",
+			wrap:     true,
+			from:     []int{2, 2},
+			to:       []int{3, 3},
+			expected: "orld\nsec",
+		},
+		{
+			name:     "wide characters",
+			content:  "日本 word",
+			from:     []int{6, 1},
+			to:       []int{8, 1},
+			expected: "wor",
+		},
+		{
+			name:     "wide characters and horizontal scroll",
+			content:  "日本 word",
+			originX:  2,
+			from:     []int{4, 1},
+			to:       []int{6, 1},
+			expected: "wor",
+		},
+		{
+			name:     "horizontal scroll",
+			originX:  6,
+			from:     []int{1, 1},
+			to:       []int{3, 1},
+			expected: "wor",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 80, Height: 24})
+			assert.NoError(t, err)
+			defer g.Close()
+
+			v, err := g.SetView("log", 0, 0, 11, 10, 0)
+			if !IsUnknownView(err) {
+				assert.NoError(t, err)
+			}
+			v.Selectable = true
+			v.Wrap = test.wrap
+			if test.content == "" {
+				test.content = "hello world\nsecond line"
+			}
+			v.SetContent(test.content)
+			v.SetOriginX(test.originX)
+			copied := []string{}
+			v.OnCopy = func(v *View, text string) error {
+				copied = append(copied, text)
+				return nil
+			}
+
+			now := time.Now()
+			mouse := func(x, y int, buttons tcell.ButtonMask) {
+				ev := g.mouseState.event(x, y, buttons, tcell.ModNone, now, g.DoubleClickInterval)
+				if ev.Type != eventNone {
+					assert.NoError(t, g.onKey(&ev))
+				}
+			}
+			for i := 0; i < max(test.clicks, 1); i++ {
+				mouse(test.from[0], test.from[1], tcell.ButtonPrimary)
+				if i < test.clicks-1 {
+					mouse(test.from[0], test.from[1], tcell.ButtonNone)
+				}
+			}
+			if test.to != nil {
+				mouse(test.to[0], test.to[1], tcell.ButtonPrimary)
+				mouse(test.to[0], test.to[1], tcell.ButtonNone)
+			} else {
+				mouse(test.from[0], test.from[1], tcell.ButtonNone)
+			}
+
+			assert.Equal(t, test.expected, v.SelectedText())
+			if assert.NotEmpty(t, copied) {
+				assert.Equal(t, test.expected, copied[len(copied)-1])
+			}
+
+			// a click elsewhere drops the selection
+			mouse(2, 5, tcell.ButtonPrimary)
+			mouse(2, 5, tcell.ButtonNone)
+			assert.Equal(t, "", v.SelectedText())
+		})
+	}
+}
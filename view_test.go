@@ -7,6 +7,8 @@ package gocui
 import (
 	"testing"
 
+	"github.com/gdamore/tcell/v2"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -203,11 +205,69 @@ func stringToCells(s string) []cell {
 func cellsToString(cells []cell) string {
 	var s string
 	for _, c := range cells {
-		s += string(c.chr)
+		s += string(c.runes())
 	}
 	return s
 }
 
+func TestWriteGraphemes(t *testing.T) {
+	tests := []struct {
+		name           string
+		stringsToWrite []string
+		expectedCells  []string
+		expectedWidth  int
+	}{
+		{
+			name:           "combining accent",
+			stringsToWrite: []string{"ae\u0301b"},
+			expectedCells:  []string{"a", "e\u0301", "b"},
+			expectedWidth:  3,
+		},
+		{
+			name:           "emoji ZWJ sequence and flag",
+			stringsToWrite: []string{"👩\u200d👩\u200d👧🇩🇪"},
+			expectedCells:  []string{"👩\u200d👩\u200d👧", "🇩🇪"},
+			expectedWidth:  4,
+		},
+		{
+			name:           "split across writes",
+			stringsToWrite: []string{"e", "\u0301"},
+			expectedCells:  []string{"e\u0301"},
+			expectedWidth:  1,
+		},
+		{
+			name:           "escape sequence in between",
+			stringsToWrite: []string{"e\x1b[31m\u0301"},
+			expectedCells:  []string{"e\u0301"},
+			expectedWidth:  1,
+		},
+		{
+			name:           "tab",
+			stringsToWrite: []string{"e\t"},
+			expectedCells:  []string{"e", " ", " ", " "},
+			expectedWidth:  4,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := NewView("name", 0, 0, 10, 10, OutputNormal)
+			for _, s := range test.stringsToWrite {
+				v.writeRunes([]rune(s))
+			}
+
+			cells := []string{}
+			width := 0
+			for _, c := range v.lines[0] {
+				cells = append(cells, string(c.runes()))
+				width += c.width()
+			}
+			assert.EqualValues(t, test.expectedCells, cells)
+			assert.EqualValues(t, test.expectedWidth, width)
+		})
+	}
+}
+
 func TestLineWrap(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -374,6 +434,87 @@ func TestLineWrap(t *testing.T) {
 			}
 
 			assert.EqualValues(t, tc.expected, resultStrings)
+
+			// each wrapped line must start at its offset in the original line
+			_, offsets := lineWrapWithOffsets(lineCells, tc.columns)
+			assert.Len(t, offsets, len(result))
+			for i, line := range result {
+				assert.EqualValues(t, cellsToString(line), cellsToString(lineCells[offsets[i]:offsets[i]+len(line)]))
+			}
 		})
 	}
 }
+
+func TestDrawGraphemes(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 20, Height: 5})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	v, _ := g.SetView("name", 0, 0, 10, 2, 0)
+	v.Frame = false
+	v.WriteString("e\u0301👩\u200d👧x")
+	v.draw()
+
+	mainc, combc, _, _ := Screen.GetContent(1, 1)
+	assert.EqualValues(t, "e\u0301", string(append([]rune{mainc}, combc...)))
+	mainc, combc, _, _ = Screen.GetContent(2, 1)
+	assert.EqualValues(t, "👩\u200d👧", string(append([]rune{mainc}, combc...)))
+	mainc, _, _, _ = Screen.GetContent(4, 1)
+	assert.EqualValues(t, 'x', mainc)
+}
+
+func TestDrawSelectionAfterGraphemes(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 20, Height: 5})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	v, _ := g.SetView("name", 0, 0, 10, 2, 0)
+	v.Frame = false
+	v.Editable = true
+	v.TextArea.TypeString("e\u0301abc")
+	v.TextArea.SetCursor(2)
+	v.TextArea.StartSelection()
+	v.TextArea.SetCursor(4)
+	v.RenderTextArea()
+	v.draw()
+
+	reversed := []bool{}
+	for x := 1; x <= 4; x++ {
+		_, _, style, _ := Screen.GetContent(x, 1)
+		_, _, attrs := style.Decompose()
+		reversed = append(reversed, attrs&tcell.AttrReverse != 0)
+	}
+	assert.EqualValues(t, []bool{false, true, true, false}, reversed)
+}
+
+func TestWordInWrappedView(t *testing.T) {
+	g, err := NewGui(NewGuiOpts{OutputMode: OutputNormal, Headless: true, Width: 20, Height: 5})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	v, _ := g.SetView("name", 0, 0, 9, 4, 0)
+	v.Wrap = true
+	v.SetContent("one two three four")
+	v.draw()
+	assert.Equal(t, "one two\nthree\nfour", v.ViewBuffer())
+
+	// positions on the wrapped lines map to the words under them
+	tests := []struct {
+		x, y     int
+		expected string
+	}{
+		{1, 0, "one"},
+		{5, 0, "two"},
+		{2, 1, "three"},
+		{0, 2, "four"},
+		{3, 2, "four"},
+	}
+	for _, test := range tests {
+		word, ok := v.Word(test.x, test.y)
+		assert.True(t, ok)
+		assert.Equal(t, test.expected, word, "at %d,%d", test.x, test.y)
+	}
+	line, ok := v.Line(2)
+	assert.True(t, ok)
+	assert.Equal(t, "one two three four", line)
+}
@@ -0,0 +1,609 @@
+package gocui
+
+import (
+	"strings"
+	"unicode"
+)
+
+// VimMode is the mode a VimEditor is in.
+type VimMode int
+
+const (
+	VimNormalMode VimMode = iota
+	VimInsertMode
+	VimVisualMode
+)
+
+func (m VimMode) String() string {
+	switch m {
+	case VimInsertMode:
+		return "INSERT"
+	case VimVisualMode:
+		return "VISUAL"
+	default:
+		return "NORMAL"
+	}
+}
+
+// VimEditor is a modal editor supporting a subset of vim's normal, insert and
+// visual mode commands. In insert mode it behaves like SimpleEditor.
+//
+// A VimEditor keeps track of its mode and of partially typed commands, so
+// every view needs its own instance:
+//
+//	editor := gocui.NewVimEditor(gocui.VimNormalMode)
+//	v.Editor = editor
+//	// ... and in the layout function:
+//	v.Subtitle = editor.Mode().String()
+type VimEditor struct {
+	mode VimMode
+
+	// count typed so far, 0 if none
+	count int
+
+	// pending operator ('c', 'd' or 'y') and the count typed before it
+	operator      rune
+	operatorCount int
+
+	// 'i' or 'a' when we're waiting for the second key of a text object
+	textObject rune
+
+	// the last yanked or deleted text, and whether it consists of whole lines
+	register string
+	linewise bool
+}
+
+// NewVimEditor returns a VimEditor in the given mode, which should be either
+// VimNormalMode or VimInsertMode.
+func NewVimEditor(mode VimMode) *VimEditor {
+	if mode != VimInsertMode {
+		mode = VimNormalMode
+	}
+
+	return &VimEditor{mode: mode}
+}
+
+// Mode returns the mode the editor is currently in.
+func (e *VimEditor) Mode() VimMode {
+	return e.mode
+}
+
+func (e *VimEditor) Edit(v *View, key Key, ch rune, mod Modifier) bool {
+	if e.mode == VimInsertMode {
+		if key == KeyEsc {
+			e.enterNormalMode()
+			if !v.TextArea.atLineStart() {
+				v.TextArea.MoveCursorLeft()
+			}
+			v.RenderTextArea()
+			return true
+		}
+
+		return SimpleEditor(v, key, ch, mod)
+	}
+
+	if mod != ModNone {
+		return false
+	}
+
+	switch key {
+	case KeyArrowLeft:
+		ch = 'h'
+	case KeyArrowDown:
+		ch = 'j'
+	case KeyArrowUp:
+		ch = 'k'
+	case KeyArrowRight:
+		ch = 'l'
+	case KeyHome:
+		ch = '0'
+	case KeyEnd:
+		ch = '$'
+	case KeyEsc:
+		if e.mode == VimVisualMode {
+			v.TextArea.ClearSelection()
+		}
+		e.enterNormalMode()
+		v.RenderTextArea()
+		return true
+	case KeyCtrlR:
+		for i := e.takeCount(); i > 0; i-- {
+			v.TextArea.Redo()
+		}
+		v.RenderTextArea()
+		return true
+	default:
+		if ch == 0 {
+			return false
+		}
+	}
+
+	if e.mode == VimVisualMode {
+		e.editVisual(v.TextArea, ch)
+	} else {
+		e.editNormal(v.TextArea, ch)
+	}
+	v.RenderTextArea()
+
+	return true
+}
+
+func (e *VimEditor) enterNormalMode() {
+	e.mode = VimNormalMode
+	e.resetPending()
+}
+
+func (e *VimEditor) enterInsertMode() {
+	e.mode = VimInsertMode
+	e.resetPending()
+}
+
+func (e *VimEditor) resetPending() {
+	e.count = 0
+	e.operator = 0
+	e.operatorCount = 0
+	e.textObject = 0
+}
+
+// takeCount returns the count typed so far, defaulting to 1, and resets it
+func (e *VimEditor) takeCount() int {
+	count := e.count
+	e.count = 0
+	if count == 0 {
+		return 1
+	}
+
+	return count
+}
+
+// typeCountDigit adds ch to the count if it's a digit that's part of a count,
+// returning false otherwise. '0' on its own is a motion, not a count.
+func (e *VimEditor) typeCountDigit(ch rune) bool {
+	if ch < '0' || ch > '9' || (ch == '0' && e.count == 0) {
+		return false
+	}
+
+	e.count = e.count*10 + int(ch-'0')
+	return true
+}
+
+func (e *VimEditor) editNormal(ta *TextArea, ch rune) {
+	if e.textObject != 0 {
+		start, end, ok := vimTextObject(ta, e.textObject, ch)
+		operator := e.operator
+		e.resetPending()
+		if ok {
+			e.applyOperator(ta, operator, start, end, false)
+		}
+		return
+	}
+
+	if e.typeCountDigit(ch) {
+		return
+	}
+
+	if motion, ok := vimMotions[ch]; ok {
+		count := e.takeCount()
+		if e.operator == 0 {
+			for i := 0; i < count; i++ {
+				motion.move(ta)
+			}
+			return
+		}
+
+		count *= e.operatorCount
+		operator := e.operator
+		e.resetPending()
+		if motion.linewise {
+			e.applyLinewiseMotion(ta, operator, motion, count)
+		} else {
+			e.applyMotion(ta, operator, motion, count)
+		}
+		return
+	}
+
+	switch ch {
+	case 'c', 'd', 'y':
+		count := e.takeCount()
+		if e.operator == 0 {
+			e.operator = ch
+			e.operatorCount = count
+			return
+		}
+
+		// cc, dd and yy operate on whole lines
+		operator := e.operator
+		count *= e.operatorCount
+		e.resetPending()
+		if operator == ch {
+			start, end := vimLinesRange(ta, ta.cursor, count)
+			e.applyOperator(ta, operator, start, end, true)
+		}
+		return
+	case 'i', 'a':
+		if e.operator != 0 {
+			e.textObject = ch
+			return
+		}
+	}
+
+	count := e.takeCount()
+	e.resetPending()
+
+	switch ch {
+	case 'i':
+		e.enterInsertMode()
+	case 'a':
+		if !ta.atLineEnd() {
+			ta.MoveCursorRight()
+		}
+		e.enterInsertMode()
+	case 'I':
+		ta.SetCursor(vimLineStart(ta, ta.cursor))
+		e.enterInsertMode()
+	case 'A':
+		ta.SetCursor(vimLineEnd(ta, ta.cursor))
+		e.enterInsertMode()
+	case 'o':
+		ta.SetCursor(vimLineEnd(ta, ta.cursor))
+		ta.TypeRune('\n')
+		e.enterInsertMode()
+	case 'O':
+		ta.SetCursor(vimLineStart(ta, ta.cursor))
+		ta.TypeRune('\n')
+		ta.MoveCursorLeft()
+		e.enterInsertMode()
+	case 'x':
+		end := ta.cursor
+		for i := 0; i < count; i++ {
+			end = nextGraphemeBoundary(ta.content, end)
+		}
+		end = min(end, vimLineEnd(ta, ta.cursor))
+		e.applyOperator(ta, 'd', ta.cursor, end, false)
+	case 'D':
+		e.applyOperator(ta, 'd', ta.cursor, vimLineEnd(ta, ta.cursor), false)
+	case 'C':
+		e.applyOperator(ta, 'c', ta.cursor, vimLineEnd(ta, ta.cursor), false)
+	case 'p':
+		for i := 0; i < count; i++ {
+			e.put(ta, true)
+		}
+	case 'P':
+		for i := 0; i < count; i++ {
+			e.put(ta, false)
+		}
+	case 'u':
+		for i := 0; i < count; i++ {
+			ta.Undo()
+		}
+	case 'v':
+		e.mode = VimVisualMode
+		ta.ClearSelection()
+		ta.StartSelection()
+	}
+}
+
+func (e *VimEditor) editVisual(ta *TextArea, ch rune) {
+	if e.typeCountDigit(ch) {
+		return
+	}
+
+	if motion, ok := vimMotions[ch]; ok {
+		for i := e.takeCount(); i > 0; i-- {
+			motion.move(ta)
+		}
+		return
+	}
+
+	e.count = 0
+
+	switch ch {
+	case 'c', 'd', 'x', 'y':
+		// unlike the text area's selection, vim's includes the character
+		// under the cursor
+		start, end := ta.GetSelectionRange()
+		end = nextGraphemeBoundary(ta.content, end)
+		ta.ClearSelection()
+		e.enterNormalMode()
+		if ch == 'x' {
+			ch = 'd'
+		}
+		e.applyOperator(ta, ch, start, end, false)
+	case 'v':
+		ta.ClearSelection()
+		e.enterNormalMode()
+	}
+}
+
+func (e *VimEditor) applyMotion(ta *TextArea, operator rune, motion vimMotion, count int) {
+	start := ta.cursor
+	for i := 0; i < count; i++ {
+		motion.move(ta)
+	}
+	end := ta.cursor
+	ta.SetCursor(start)
+
+	if motion.inclusive {
+		end = nextGraphemeBoundary(ta.content, end)
+	}
+	if start > end {
+		start, end = end, start
+	}
+
+	// like vim, don't let a word motion swallow the end of the line
+	if motion.key == 'w' && end > vimLineEnd(ta, start) {
+		end = vimLineEnd(ta, start)
+	}
+
+	e.applyOperator(ta, operator, start, end, false)
+}
+
+func (e *VimEditor) applyLinewiseMotion(ta *TextArea, operator rune, motion vimMotion, count int) {
+	start := ta.cursor
+	for i := 0; i < count; i++ {
+		motion.move(ta)
+	}
+	end := ta.cursor
+
+	if start > end {
+		start, end = end, start
+	}
+	lineCount := strings.Count(string(ta.content[start:end]), "\n") + 1
+	from, to := vimLinesRange(ta, start, lineCount)
+	e.applyOperator(ta, operator, from, to, true)
+}
+
+// applyOperator applies the operator to the text from start to end. If
+// linewise is true, the range must span whole lines, as returned by
+// vimLinesRange.
+func (e *VimEditor) applyOperator(ta *TextArea, operator rune, start int, end int, linewise bool) {
+	if start >= end {
+		if operator == 'c' {
+			e.enterInsertMode()
+		}
+		return
+	}
+
+	// undoing the change should put the cursor back where it was rather than
+	// where the selection ended
+	defer ta.trackEdit(editOther)()
+
+	text := string(ta.content[start:end])
+	// if the lines are at the end of the content, vimLinesRange includes the
+	// line break before them rather than after them
+	leadingLineBreak := false
+	if linewise && !strings.HasSuffix(text, "\n") {
+		// store whole lines with a trailing line break either way
+		if start > 0 {
+			leadingLineBreak = true
+			text = text[1:]
+		}
+		text += "\n"
+	}
+	e.register = text
+	e.linewise = linewise
+	ta.pushKill(text)
+
+	switch operator {
+	case 'y':
+		ta.SetCursor(start)
+	case 'd':
+		ta.Select(start, end)
+		ta.DeleteSelection()
+		if linewise {
+			ta.SetCursor(vimLineStart(ta, ta.cursor))
+		}
+	case 'c':
+		if linewise {
+			// keep a line, but replace its content
+			if leadingLineBreak {
+				start++
+			}
+			end = vimLineEnd(ta, max(start, end-1))
+		}
+		ta.Select(start, end)
+		ta.DeleteSelection()
+		e.enterInsertMode()
+	}
+}
+
+// put pastes the register after (or before) the cursor, or below (or above)
+// the current line if the register holds whole lines.
+func (e *VimEditor) put(ta *TextArea, after bool) {
+	text := ta.getClipboard()
+	if text == "" {
+		return
+	}
+
+	if !e.linewise || text != e.register {
+		if after && !ta.atLineEnd() {
+			ta.MoveCursorRight()
+		}
+		ta.TypeString(text)
+		ta.MoveCursorLeft()
+		return
+	}
+
+	if !after {
+		ta.SetCursor(vimLineStart(ta, ta.cursor))
+		ta.TypeString(text)
+		ta.SetCursor(ta.cursor - len([]rune(text)))
+		return
+	}
+
+	lineEnd := vimLineEnd(ta, ta.cursor)
+	if lineEnd == len(ta.content) {
+		ta.SetCursor(lineEnd)
+		ta.TypeString("\n" + strings.TrimSuffix(text, "\n"))
+		ta.SetCursor(lineEnd + 1)
+		return
+	}
+
+	ta.SetCursor(lineEnd + 1)
+	ta.TypeString(text)
+	ta.SetCursor(lineEnd + 1)
+}
+
+type vimMotion struct {
+	key  rune
+	move func(*TextArea)
+	// an inclusive motion's operator range includes the character it moves to
+	inclusive bool
+	// a linewise motion's operator range consists of whole lines
+	linewise bool
+}
+
+var vimMotions = map[rune]vimMotion{}
+
+func init() {
+	motions := []vimMotion{
+		{key: 'h', move: func(ta *TextArea) {
+			if !ta.atLineStart() {
+				ta.MoveCursorLeft()
+			}
+		}},
+		{key: 'l', move: func(ta *TextArea) {
+			if !ta.atLineEnd() {
+				ta.MoveCursorRight()
+			}
+		}},
+		{key: 'j', move: (*TextArea).MoveCursorDown, linewise: true},
+		{key: 'k', move: (*TextArea).MoveCursorUp, linewise: true},
+		{key: 'w', move: vimNextWordStart},
+		{key: 'b', move: vimPrevWordStart},
+		{key: 'e', move: vimWordEnd, inclusive: true},
+		{key: '0', move: func(ta *TextArea) { ta.SetCursor(vimLineStart(ta, ta.cursor)) }},
+		{key: '$', move: func(ta *TextArea) { ta.SetCursor(vimLineEnd(ta, ta.cursor)) }},
+	}
+
+	for _, motion := range motions {
+		vimMotions[motion.key] = motion
+	}
+}
+
+// vimCharClass distinguishes whitespace (0), word characters (1) and other
+// characters (2), as vim does when moving by words.
+func vimCharClass(r rune) int {
+	switch {
+	case unicode.IsSpace(r):
+		return 0
+	case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+		return 1
+	default:
+		return 2
+	}
+}
+
+func vimNextWordStart(ta *TextArea) {
+	c := ta.cursor
+	if c < len(ta.content) {
+		class := vimCharClass(ta.content[c])
+		for c < len(ta.content) && class != 0 && vimCharClass(ta.content[c]) == class {
+			c++
+		}
+	}
+	for c < len(ta.content) && vimCharClass(ta.content[c]) == 0 {
+		c++
+	}
+	ta.SetCursor(c)
+}
+
+func vimPrevWordStart(ta *TextArea) {
+	c := ta.cursor
+	for c > 0 && vimCharClass(ta.content[c-1]) == 0 {
+		c--
+	}
+	if c > 0 {
+		class := vimCharClass(ta.content[c-1])
+		for c > 0 && vimCharClass(ta.content[c-1]) == class {
+			c--
+		}
+	}
+	ta.SetCursor(c)
+}
+
+func vimWordEnd(ta *TextArea) {
+	c := ta.cursor + 1
+	for c < len(ta.content) && vimCharClass(ta.content[c]) == 0 {
+		c++
+	}
+	if c >= len(ta.content) {
+		ta.SetCursor(len(ta.content))
+		return
+	}
+	class := vimCharClass(ta.content[c])
+	for c+1 < len(ta.content) && vimCharClass(ta.content[c+1]) == class {
+		c++
+	}
+	ta.SetCursor(c)
+}
+
+// vimTextObject returns the range of the text object (e.g. "iw") at the cursor.
+func vimTextObject(ta *TextArea, prefix rune, ch rune) (int, int, bool) {
+	if ch != 'w' || ta.cursor >= len(ta.content) || ta.content[ta.cursor] == '\n' {
+		return 0, 0, false
+	}
+
+	class := vimCharClass(ta.content[ta.cursor])
+	start, end := ta.cursor, ta.cursor
+	for start > 0 && ta.content[start-1] != '\n' && vimCharClass(ta.content[start-1]) == class {
+		start--
+	}
+	for end < len(ta.content) && ta.content[end] != '\n' && vimCharClass(ta.content[end]) == class {
+		end++
+	}
+
+	if prefix == 'a' {
+		// "a word" includes the whitespace after the word, or before it if
+		// there's none after it
+		trailing := end
+		for trailing < len(ta.content) && ta.content[trailing] != '\n' && vimCharClass(ta.content[trailing]) == 0 {
+			trailing++
+		}
+		if trailing > end {
+			end = trailing
+		} else {
+			for start > 0 && ta.content[start-1] != '\n' && vimCharClass(ta.content[start-1]) == 0 {
+				start--
+			}
+		}
+	}
+
+	return start, end, true
+}
+
+func vimLineStart(ta *TextArea, c int) int {
+	for c > 0 && ta.content[c-1] != '\n' {
+		c--
+	}
+	return c
+}
+
+func vimLineEnd(ta *TextArea, c int) int {
+	for c < len(ta.content) && ta.content[c] != '\n' {
+		c++
+	}
+	return c
+}
+
+// vimLinesRange returns the range spanning count lines starting with the one
+// containing c, including their line breaks. If the last of those lines is
+// the last line of the content, the line break before the first line is
+// included instead, so that deleting the range leaves no empty line behind.
+func vimLinesRange(ta *TextArea, c int, count int) (int, int) {
+	start := vimLineStart(ta, c)
+	end := start
+	for i := 0; i < count; i++ {
+		end = vimLineEnd(ta, end)
+		if end == len(ta.content) {
+			break
+		}
+		end++
+	}
+
+	if end == len(ta.content) && start > 0 && (end == 0 || ta.content[end-1] != '\n') {
+		start--
+	}
+
+	return start, end
+}
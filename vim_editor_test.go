@@ -0,0 +1,266 @@
+package gocui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVimEditor(t *testing.T) {
+	tests := []struct {
+		name            string
+		content         string
+		cursor          int
+		keys            string
+		expectedContent string
+		expectedCursor  int
+		expectedMode    VimMode
+	}{
+		{
+			name:            "hjkl",
+			content:         "abc\ndef",
+			cursor:          0,
+			keys:            "lljh",
+			expectedContent: "abc\ndef",
+			expectedCursor:  5,
+			expectedMode:    VimNormalMode,
+		},
+		{
+			name:            "h doesn't leave the line",
+			content:         "abc\ndef",
+			cursor:          4,
+			keys:            "h",
+			expectedContent: "abc\ndef",
+			expectedCursor:  4,
+			expectedMode:    VimNormalMode,
+		},
+		{
+			name:            "word motions",
+			content:         "foo bar.baz qux",
+			cursor:          0,
+			keys:            "wwwbe",
+			expectedContent: "foo bar.baz qux",
+			expectedCursor:  10,
+			expectedMode:    VimNormalMode,
+		},
+		{
+			name:            "count",
+			content:         "one two three four",
+			cursor:          0,
+			keys:            "3w",
+			expectedContent: "one two three four",
+			expectedCursor:  14,
+			expectedMode:    VimNormalMode,
+		},
+		{
+			name:            "0 and $",
+			content:         "abc def",
+			cursor:          3,
+			keys:            "$0",
+			expectedContent: "abc def",
+			expectedCursor:  0,
+			expectedMode:    VimNormalMode,
+		},
+		{
+			name:            "x",
+			content:         "abcdef",
+			cursor:          1,
+			keys:            "2x",
+			expectedContent: "adef",
+			expectedCursor:  1,
+			expectedMode:    VimNormalMode,
+		},
+		{
+			name:            "dw",
+			content:         "foo bar baz",
+			cursor:          4,
+			keys:            "dw",
+			expectedContent: "foo baz",
+			expectedCursor:  4,
+			expectedMode:    VimNormalMode,
+		},
+		{
+			name:            "dw at the end of a line keeps the line break",
+			content:         "foo bar\nbaz",
+			cursor:          4,
+			keys:            "dw",
+			expectedContent: "foo \nbaz",
+			expectedCursor:  4,
+			expectedMode:    VimNormalMode,
+		},
+		{
+			name:            "de",
+			content:         "foo bar baz",
+			cursor:          4,
+			keys:            "de",
+			expectedContent: "foo  baz",
+			expectedCursor:  4,
+			expectedMode:    VimNormalMode,
+		},
+		{
+			name:            "dd",
+			content:         "one\ntwo\nthree",
+			cursor:          5,
+			keys:            "dd",
+			expectedContent: "one\nthree",
+			expectedCursor:  4,
+			expectedMode:    VimNormalMode,
+		},
+		{
+			name:            "dd on the last line",
+			content:         "one\ntwo\nthree",
+			cursor:          9,
+			keys:            "dd",
+			expectedContent: "one\ntwo",
+			expectedCursor:  4,
+			expectedMode:    VimNormalMode,
+		},
+		{
+			name:            "count with dd",
+			content:         "one\ntwo\nthree",
+			cursor:          0,
+			keys:            "2dd",
+			expectedContent: "three",
+			expectedCursor:  0,
+			expectedMode:    VimNormalMode,
+		},
+		{
+			name:            "dj",
+			content:         "one\ntwo\nthree",
+			cursor:          5,
+			keys:            "dj",
+			expectedContent: "one",
+			expectedCursor:  0,
+			expectedMode:    VimNormalMode,
+		},
+		{
+			name:            "yy and p",
+			content:         "one\ntwo",
+			cursor:          1,
+			keys:            "yyjp",
+			expectedContent: "one\ntwo\none",
+			expectedCursor:  8,
+			expectedMode:    VimNormalMode,
+		},
+		{
+			name:            "dd and P",
+			content:         "one\ntwo",
+			cursor:          0,
+			keys:            "ddP",
+			expectedContent: "one\ntwo",
+			expectedCursor:  0,
+			expectedMode:    VimNormalMode,
+		},
+		{
+			name:            "x and p",
+			content:         "abc",
+			cursor:          0,
+			keys:            "xp",
+			expectedContent: "bac",
+			expectedCursor:  1,
+			expectedMode:    VimNormalMode,
+		},
+		{
+			name:            "ciw",
+			content:         "foo bar baz",
+			cursor:          5,
+			keys:            "ciwxy",
+			expectedContent: "foo xy baz",
+			expectedCursor:  6,
+			expectedMode:    VimInsertMode,
+		},
+		{
+			name:            "daw",
+			content:         "foo bar baz",
+			cursor:          5,
+			keys:            "daw",
+			expectedContent: "foo baz",
+			expectedCursor:  4,
+			expectedMode:    VimNormalMode,
+		},
+		{
+			name:            "cc",
+			content:         "one\ntwo\nthree",
+			cursor:          5,
+			keys:            "ccx",
+			expectedContent: "one\nx\nthree",
+			expectedCursor:  5,
+			expectedMode:    VimInsertMode,
+		},
+		{
+			name:            "u",
+			content:         "foo bar baz",
+			cursor:          0,
+			keys:            "dwdwuu",
+			expectedContent: "foo bar baz",
+			expectedCursor:  0,
+			expectedMode:    VimNormalMode,
+		},
+		{
+			name:            "i and a",
+			content:         "ac",
+			cursor:          0,
+			keys:            "ab",
+			expectedContent: "abc",
+			expectedCursor:  2,
+			expectedMode:    VimInsertMode,
+		},
+		{
+			name:            "o",
+			content:         "one\ntwo",
+			cursor:          0,
+			keys:            "ox",
+			expectedContent: "one\nx\ntwo",
+			expectedCursor:  5,
+			expectedMode:    VimInsertMode,
+		},
+		{
+			name:            "visual mode",
+			content:         "foo bar baz",
+			cursor:          4,
+			keys:            "vlld",
+			expectedContent: "foo  baz",
+			expectedCursor:  4,
+			expectedMode:    VimNormalMode,
+		},
+		{
+			name:            "visual mode yank",
+			content:         "foo bar",
+			cursor:          0,
+			keys:            "vey$p",
+			expectedContent: "foo barfoo",
+			expectedCursor:  9,
+			expectedMode:    VimNormalMode,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := NewView("name", 0, 0, 20, 10, OutputNormal)
+			v.TextArea.TypeString(test.content)
+			v.TextArea.SetCursor(test.cursor)
+			editor := NewVimEditor(VimNormalMode)
+			for _, ch := range test.keys {
+				editor.Edit(v, 0, ch, ModNone)
+			}
+			assert.EqualValues(t, test.expectedContent, v.TextArea.GetUnwrappedContent())
+			assert.EqualValues(t, test.expectedCursor, v.TextArea.GetCursor())
+			assert.EqualValues(t, test.expectedMode, editor.Mode())
+		})
+	}
+}
+
+func TestVimEditorEscape(t *testing.T) {
+	v := NewView("name", 0, 0, 20, 10, OutputNormal)
+	editor := NewVimEditor(VimInsertMode)
+
+	for _, ch := range "abc" {
+		editor.Edit(v, 0, ch, ModNone)
+	}
+	editor.Edit(v, KeyEsc, 0, ModNone)
+	assert.EqualValues(t, VimNormalMode, editor.Mode())
+	assert.EqualValues(t, 2, v.TextArea.GetCursor())
+
+	// keys the editor doesn't know about are left to keybindings
+	assert.False(t, editor.Edit(v, KeyCtrlC, 0, ModNone))
+}